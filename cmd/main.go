@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,9 +20,14 @@ import (
 	pb "github.com/GalitskyKK/nekkus-core/pkg/protocol"
 
 	"github.com/GalitskyKK/nekkus-eye/assets"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
 	"github.com/GalitskyKK/nekkus-eye/internal/module"
 	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/rules"
 	"github.com/GalitskyKK/nekkus-eye/internal/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/settings"
+	"github.com/GalitskyKK/nekkus-eye/internal/snapshots"
+	"github.com/GalitskyKK/nekkus-eye/internal/watchlist"
 	"github.com/GalitskyKK/nekkus-eye/ui"
 
 	"google.golang.org/grpc"
@@ -36,6 +42,8 @@ var (
 	hubAddr  = flag.String("hub-addr", "", "Hub gRPC address when started by Hub")
 	addr     = flag.String("addr", "", "gRPC listen address (e.g. 127.0.0.1:19002)")
 	dataDirF = flag.String("data-dir", "", "Data directory (overrides default)")
+	smiPathF = flag.String("nvidia-smi", "", "Path to nvidia-smi (default: from PATH or NEKKUS_EYE_NVIDIA_SMI)")
+	originsF = flag.String("allow-origin", "", "Comma-separated browser origins allowed to use the API besides Eye's own UI (e.g. the Hub UI)")
 )
 
 func waitForServer(host string, port int, timeout time.Duration) {
@@ -71,12 +79,58 @@ func main() {
 		dataDir = config.GetDataDir("eye")
 	}
 
-	collector := monitor.NewCollector(1 * time.Second)
+	nvidiaSMIPath := *smiPathF
+	if nvidiaSMIPath == "" {
+		nvidiaSMIPath = os.Getenv("NEKKUS_EYE_NVIDIA_SMI")
+	}
+	collector := monitor.NewCollectorWithConfig(monitor.CollectorConfig{
+		Interval:      1 * time.Second,
+		NvidiaSMIPath: nvidiaSMIPath,
+	})
 	defer collector.Stop()
 
 	uiFS, _ := fs.Sub(ui.Assets, "frontend/dist")
 	srv := coreserver.New(*httpPort, grpcPortVal, uiFS)
-	server.RegisterRoutes(srv, collector)
+	store, err := settings.Load(dataDir)
+	if err != nil {
+		log.Printf("Settings error: %v (using defaults)", err)
+	}
+	// Под Hub родитель Eye — сам Hub: его завершать нельзя.
+	var hubPID int32
+	if *mode == "hub" {
+		hubPID = int32(os.Getppid())
+	}
+	guard := monitor.NewProcessGuard(monitor.GuardConfig{
+		HubPID:         hubPID,
+		ProtectedNames: func() []string { return store.Get().ProtectedNames },
+	})
+	auditLog, err := audit.Open(dataDir)
+	if err != nil {
+		log.Printf("Audit log error: %v (actions will not be recorded)", err)
+	}
+	defer auditLog.Close()
+	watch, err := watchlist.Load(dataDir, collector)
+	if err != nil {
+		log.Printf("Watchlist error: %v", err)
+	}
+	go watch.Run(ctx)
+	ruleEngine, err := rules.Load(rules.Config{
+		DataDir:        dataDir,
+		Collector:      collector,
+		Guard:          guard,
+		Audit:          auditLog,
+		TerminateGrace: func() time.Duration { return store.Get().TerminateGrace() },
+	})
+	if err != nil {
+		log.Printf("Rules error: %v", err)
+	}
+	go ruleEngine.Run(ctx)
+	snaps, err := snapshots.Load(dataDir, collector)
+	if err != nil {
+		log.Printf("Snapshots error: %v", err)
+	}
+	server.AllowOrigins(strings.Split(*originsF, ",")...)
+	server.RegisterRoutes(srv, collector, store, watch, guard, auditLog, ruleEngine, snaps)
 
 	go func() {
 		if err := srv.Start(ctx); err != nil {
@@ -84,7 +138,7 @@ func main() {
 		}
 	}()
 
-	mod := module.New(collector, *httpPort)
+	mod := module.New(collector, *httpPort, store, watch, guard, auditLog, ruleEngine)
 	go func() {
 		if err := srv.StartGRPC(func(s *grpc.Server) {
 			pb.RegisterNekkusModuleServer(s, mod)
@@ -10,7 +10,7 @@ import {
   Section,
   StatusDot,
 } from '@nekkus/ui-kit'
-import { fetchStats, fetchProcesses, killProcess, type Stats, type ProcessInfo } from './api'
+import { fetchStats, fetchProcesses, killProcess, ProtectionError, type Stats, type ProcessInfo } from './api'
 
 const REFRESH_MS = 2000
 const CHART_POINTS = 30
@@ -103,8 +103,8 @@ function ProcessesPanel({
       setProcessLoading(true)
       setProcessError(null)
       try {
-        const list = await fetchProcesses({ q: q || undefined, limit: 100, with_metrics: true })
-        setProcessList(list)
+        const page = await fetchProcesses({ q: q || undefined, sort: 'cpu', limit: 100, with_metrics: true })
+        setProcessList(page.processes)
       } catch (e) {
         setProcessError(e instanceof Error ? e.message : 'Ошибка загрузки')
         setProcessList([])
@@ -125,7 +125,16 @@ function ProcessesPanel({
       if (!window.confirm(`Завершить процесс ${pid}?`)) return
       setProcessKilling(pid)
       try {
-        await killProcess(pid)
+        try {
+          await killProcess(pid)
+        } catch (err) {
+          // Защищённый процесс: показываем причину и повторяем с токеном подтверждения.
+          if (!(err instanceof ProtectionError) || !err.needsConfirmation) throw err
+          const check = err.protection.checks.find((c) => c.decision === 'confirm')
+          const what = check ? `${check.name ?? check.pid}: ${check.reason}` : `PID ${pid}`
+          if (!window.confirm(`Процесс защищён (${what}). Всё равно завершить?`)) return
+          await killProcess(pid, { confirmToken: err.protection.confirm_token })
+        }
         setProcessList((prev) => prev.filter((p) => p.pid !== pid))
       } catch (err) {
         window.alert(err instanceof Error ? err.message : 'Ошибка')
@@ -729,6 +738,22 @@ export default function App() {
                       <dd>{formatMB(stats.gpu_memory_used_mb ?? 0)} / {formatMB(stats.gpu_memory_total_mb ?? 0)}</dd>
                     </>
                   )}
+                  {stats.gpu_freq_mhz != null && stats.gpu_freq_mhz > 0 && (
+                    <>
+                      <dt>Частота</dt>
+                      <dd>
+                        {stats.gpu_freq_mhz} МГц
+                        {stats.gpu_max_freq_mhz ? ` / ${stats.gpu_max_freq_mhz} МГц` : ''}
+                      </dd>
+                    </>
+                  )}
+                  {stats.gpu_engines &&
+                    Object.entries(stats.gpu_engines).map(([engine, pct]) => (
+                      <React.Fragment key={engine}>
+                        <dt>{engine}</dt>
+                        <dd>{pct.toFixed(1)}%</dd>
+                      </React.Fragment>
+                    ))}
                 </dl>
               </Card>
               <p className="eye-chart-hint">
@@ -7,6 +7,29 @@ export interface EyeTopProcess {
   rss_mb?: number
 }
 
+export interface GPUInfo {
+  index: number
+  vendor: string
+  bus_id?: string
+  name: string
+  utilization_percent: number
+  memory_used_mb?: number
+  memory_total_mb?: number
+  temp_c?: number
+  power_w?: number
+  power_limit_w?: number
+  fan_percent?: number
+  freq_mhz?: number
+  max_freq_mhz?: number
+  memory_freq_mhz?: number
+  engines?: Record<string, number>
+  encoder_percent?: number
+  decoder_percent?: number
+  pcie_rx_mb_s?: number
+  pcie_tx_mb_s?: number
+  throttle_reasons?: string[]
+}
+
 export interface Stats {
   cpu_percent: number
   cpu_model_name?: string
@@ -32,6 +55,11 @@ export interface Stats {
   gpu_temp_c?: number
   gpu_memory_used_mb?: number
   gpu_memory_total_mb?: number
+  gpu_vendor?: string
+  gpu_freq_mhz?: number
+  gpu_max_freq_mhz?: number
+  gpu_engines?: Record<string, number>
+  gpus?: GPUInfo[]
   hostname?: string
   platform?: string
   os?: string
@@ -39,21 +67,45 @@ export interface Stats {
   kernel_version?: string
   uptime_sec?: number
   process_count?: number
+  zombie_count?: number
+  blocked_count?: number // в непрерываемом сне (D)
+  stopped_count?: number
   net_bytes_sent?: number
   net_bytes_recv?: number
   timestamp: number
   top_processes?: EyeTopProcess[]
+  top_gpu_processes?: ProcessInfo[]
+  top_io_processes?: ProcessInfo[]
 }
 
 export interface ProcessInfo {
   pid: number
   name: string
   rss_mb?: number
+  pss_mb?: number
+  uss_mb?: number
+  shared_mb?: number
+  swap_mb?: number
   status?: string
+  username?: string
+  start_time?: number
   cpu_percent?: number
+  disk_read_bps?: number
+  disk_write_bps?: number
+  read_syscalls?: number
+  write_syscalls?: number
+  read_syscalls_per_sec?: number
+  write_syscalls_per_sec?: number
+  io_denied?: boolean
   net_bytes_sent?: number
   net_bytes_recv?: number
   connections_count?: number
+  gpu_percent?: number
+  gpu_memory_mb?: number
+  gpu_engines?: Record<string, number>
+  nice?: number
+  affinity?: string
+  io_priority?: string
 }
 
 export async function fetchStats(): Promise<Stats> {
@@ -62,25 +114,870 @@ export async function fetchStats(): Promise<Stats> {
   return res.json()
 }
 
-export async function fetchProcesses(params?: { q?: string; limit?: number; with_metrics?: boolean }): Promise<ProcessInfo[]> {
+export type ProcessSort =
+  | 'pid'
+  | 'name'
+  | 'cpu'
+  | 'rss'
+  | 'pss'
+  | 'uss'
+  | 'swap'
+  | 'connections'
+  | 'start_time'
+  | 'gpu'
+  | 'gpu_memory'
+  | 'disk_read'
+  | 'disk_write'
+  | 'disk_io'
+  | 'count'
+
+export interface ProcessQuery {
+  q?: string
+  sort?: ProcessSort
+  order?: 'asc' | 'desc'
+  name?: string
+  cmdline?: string
+  user?: string
+  status?: string[]
+  min_cpu?: number
+  min_rss_mb?: number
+  limit?: number
+  offset?: number
+  cursor?: string
+  with_metrics?: boolean
+}
+
+export type ProcessGroupBy = 'app' | 'pgid' | 'session'
+
+export interface ProcessGroup {
+  key: string
+  name: string
+  exe?: string
+  leader_pid: number
+  count: number
+  cpu_percent: number
+  rss_mb: number
+  pss_mb?: number
+  uss_mb?: number
+  swap_mb?: number
+  disk_read_bps?: number
+  disk_write_bps?: number
+  gpu_percent?: number
+  gpu_memory_mb?: number
+  connections_count?: number
+  pids: number[]
+  members?: ProcessInfo[]
+}
+
+export interface ProcessGroupPage {
+  groups: ProcessGroup[]
+  total: number
+  processes: number
+  offset: number
+  limit: number
+  next_cursor?: string
+}
+
+export interface ProcessPage {
+  processes: ProcessInfo[]
+  total: number
+  offset: number
+  limit: number
+  next_cursor?: string
+}
+
+function processSearchParams(params?: Record<string, unknown>): URLSearchParams {
   const sp = new URLSearchParams()
-  if (params?.q) sp.set('q', params.q)
-  if (params?.limit) sp.set('limit', String(params.limit))
-  if (params?.with_metrics) sp.set('with_metrics', '1')
+  for (const [key, value] of Object.entries(params ?? {})) {
+    if (value === undefined || value === '' || value === false) continue
+    if (value === true) sp.set(key, '1')
+    else if (Array.isArray(value)) { if (value.length) sp.set(key, value.join(',')) }
+    else sp.set(key, String(value))
+  }
+  return sp
+}
+
+export async function fetchProcesses(params?: ProcessQuery): Promise<ProcessPage> {
+  const sp = processSearchParams({ ...params })
   const url = `${BASE}/api/processes${sp.toString() ? `?${sp}` : ''}`
   const res = await fetch(url)
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({})) as { error?: string }
+    throw new Error(err.error || res.statusText)
+  }
+  return res.json()
+}
+
+export async function fetchProcessGroups(
+  group: ProcessGroupBy,
+  params?: ProcessQuery & { members?: boolean },
+): Promise<ProcessGroupPage> {
+  const sp = processSearchParams({ ...params, group })
+  const res = await fetch(`${BASE}/api/processes?${sp}`)
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({})) as { error?: string }
+    throw new Error(err.error || res.statusText)
+  }
+  return res.json()
+}
+
+export interface ProtectionCheck {
+  pid: number
+  name?: string
+  decision: 'allow' | 'confirm' | 'deny'
+  reason?: string
+}
+
+export interface Authorization {
+  action: string
+  allowed: boolean
+  checks: ProtectionCheck[]
+  confirm_token?: string
+  confirm_expires_in_ms?: number
+}
+
+// Действие остановлено защитой процессов: 403 — запрещено, 428 — нужен confirm_token.
+export class ProtectionError extends Error {
+  constructor(message: string, readonly status: number, readonly protection: Authorization) {
+    super(message)
+  }
+
+  get needsConfirmation(): boolean {
+    return this.status === 428 && !!this.protection.confirm_token
+  }
+}
+
+export interface GuardOptions {
+  confirmToken?: string
+  dryRun?: boolean
+}
+
+function guardBody(opts?: GuardOptions): Record<string, unknown> {
+  return { confirm_token: opts?.confirmToken, dry_run: opts?.dryRun || undefined }
+}
+
+async function postGuarded<T>(path: string, body: Record<string, unknown>): Promise<T> {
+  const res = await fetch(`${BASE}/api/processes/${path}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  })
+  const data = await res.json().catch(() => ({})) as T & { error?: string; protection?: Authorization }
+  if (data.protection && (res.status === 403 || res.status === 428)) {
+    throw new ProtectionError(data.error || res.statusText, res.status, data.protection)
+  }
+  if (!res.ok) throw new Error(data.error || res.statusText)
+  return data
+}
+
+export async function killProcess(pid: number, opts?: GuardOptions): Promise<void> {
+  await postGuarded('kill', { pid, ...guardBody(opts) })
+}
+
+export async function killProcessTree(pid: number, opts?: GuardOptions): Promise<{ killed: number[]; failed?: Record<string, string> }> {
+  const data = await postGuarded<{ result?: { killed: number[]; failed?: Record<string, string> } }>(
+    'kill-tree',
+    { pid, ...guardBody(opts) },
+  )
+  return data.result ?? { killed: [] }
+}
+
+export interface ProcessConnection {
+  fd?: number
+  protocol: string
+  local_addr?: string
+  local_port?: number
+  remote_addr?: string
+  remote_port?: number
+  remote_host?: string
+  status?: string
+}
+
+export interface ProcessDetail {
+  pid: number
+  ppid: number
+  name: string
+  exe?: string
+  cmdline?: string
+  args?: string[]
+  cwd?: string
+  username?: string
+  uids?: number[]
+  status?: string
+  start_time?: number
+  elapsed_sec?: number
+  num_threads?: number
+  nice: number
+  open_files?: number
+  rlimits?: { resource: string; soft: number; hard: number }[]
+  cpu_times?: { user: number; system: number; iowait?: number }
+  memory?: {
+    rss: number
+    vms: number
+    hwm?: number
+    data?: number
+    swap?: number
+    pss?: number
+    uss?: number
+    shared?: number
+    swap_pss?: number
+  }
+  connections?: ProcessConnection[]
+  environ?: string[]
+}
+
+export async function fetchProcessDetail(pid: number, withEnv = false): Promise<ProcessDetail> {
+  const res = await fetch(`${BASE}/api/processes/${pid}${withEnv ? '?env=1' : ''}`)
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({})) as { error?: string }
+    throw new Error(err.error || res.statusText)
+  }
+  return res.json()
+}
+
+export interface ProcessNode extends ProcessInfo {
+  ppid: number
+  tree_cpu_percent: number
+  tree_rss_mb: number
+  tree_count: number
+  children?: ProcessNode[]
+}
+
+export async function fetchProcessTree(pid?: number): Promise<ProcessNode[]> {
+  const res = await fetch(`${BASE}/api/processes/tree${pid ? `?pid=${pid}` : ''}`)
   if (!res.ok) throw new Error(res.statusText)
   return res.json()
 }
 
-export async function killProcess(pid: number): Promise<void> {
-  const res = await fetch(`${BASE}/api/processes/kill`, {
+export type SignalOutcome =
+  | 'exited'
+  | 'still_running'
+  | 'suspended'
+  | 'resumed'
+  | 'permission_denied'
+  | 'not_found'
+  | 'not_supported'
+  | 'invalid_signal'
+  | 'error'
+
+export interface SignalResult {
+  pid: number
+  action: 'terminate' | 'suspend' | 'resume' | 'signal'
+  signal?: string
+  outcome: SignalOutcome
+  escalated?: boolean
+  elapsed_ms: number
+  error?: string
+}
+
+async function postSignal(path: string, body: Record<string, unknown>): Promise<SignalResult> {
+  const res = await fetch(`${BASE}/api/processes/${path}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  })
+  const data = await res.json().catch(() => ({})) as Partial<SignalResult> & { protection?: Authorization }
+  if (data.protection && (res.status === 403 || res.status === 428)) {
+    throw new ProtectionError(data.error || res.statusText, res.status, data.protection)
+  }
+  if (!data.outcome) throw new Error(data.error || res.statusText)
+  return data as SignalResult
+}
+
+export const terminateProcess = (pid: number, graceMs?: number, opts?: GuardOptions) =>
+  postSignal('terminate', { pid, grace_ms: graceMs, ...guardBody(opts) })
+export const suspendProcess = (pid: number, opts?: GuardOptions) => postSignal('suspend', { pid, ...guardBody(opts) })
+export const resumeProcess = (pid: number) => postSignal('resume', { pid })
+export const signalProcess = (pid: number, signal: string, opts?: GuardOptions) =>
+  postSignal('signal', { pid, signal, ...guardBody(opts) })
+
+export interface RestartResult {
+  pid: number
+  new_pid?: number
+  outcome: SignalOutcome | 'restarted'
+  exe?: string
+  args?: string[]
+  cwd?: string
+  env_vars: number
+  cwd_inherited?: boolean
+  env_inherited?: boolean
+  terminate?: SignalResult
+  elapsed_ms: number
+  error?: string
+}
+
+// Завершает процесс и запускает его заново с теми же exe, аргументами, каталогом и окружением.
+// Если каталог или окружение не прочитать, без inherit — outcome not_supported.
+export async function restartProcess(pid: number, graceMs?: number, opts?: GuardOptions, inherit?: boolean): Promise<RestartResult> {
+  const res = await fetch(`${BASE}/api/processes/restart`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
-    body: JSON.stringify({ pid }),
+    body: JSON.stringify({ pid, grace_ms: graceMs, inherit, ...guardBody(opts) }),
   })
+  const data = await res.json().catch(() => ({})) as Partial<RestartResult> & { protection?: Authorization }
+  if (data.protection && (res.status === 403 || res.status === 428)) {
+    throw new ProtectionError(data.error || res.statusText, res.status, data.protection)
+  }
+  if (!data.outcome) throw new Error(data.error || res.statusText)
+  return data as RestartResult
+}
+
+export interface ProcessPriority {
+  nice: number
+  affinity?: string
+  io_class?: 'none' | 'realtime' | 'best-effort' | 'idle'
+  io_level?: number
+}
+
+export interface PriorityChange {
+  nice?: number
+  affinity?: string
+  io_class?: ProcessPriority['io_class']
+  io_level?: number
+}
+
+export interface PriorityResult {
+  pid: number
+  outcome: 'applied' | 'permission_denied' | 'not_found' | 'not_supported' | 'invalid_argument' | 'error'
+  error?: string
+  priority?: ProcessPriority
+}
+
+export async function setProcessPriority(pid: number, change: PriorityChange, tree = false, opts?: GuardOptions): Promise<PriorityResult[]> {
+  const res = await fetch(`${BASE}/api/processes/priority`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ pid, tree, ...change, ...guardBody(opts) }),
+  })
+  const data = await res.json().catch(() => ({})) as PriorityResult & { results?: PriorityResult[]; protection?: Authorization }
+  if (data.protection && (res.status === 403 || res.status === 428)) {
+    throw new ProtectionError(data.error || res.statusText, res.status, data.protection)
+  }
+  if (data.results) return data.results
+  if (!data.outcome) throw new Error(data.error || res.statusText)
+  return [data]
+}
+
+export async function fetchProcessConnections(pid: number, resolve = false): Promise<ProcessConnection[]> {
+  const res = await fetch(`${BASE}/api/processes/${pid}/connections${resolve ? '?resolve=1' : ''}`)
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({})) as { error?: string }
+    throw new Error(err.error || res.statusText)
+  }
+  return res.json()
+}
+
+export interface ThreadInfo {
+  tid: number
+  name: string
+  state: string
+  cpu_percent: number
+  user_sec: number
+  system_sec: number
+  processor: number
+  affinity?: string
+  start_time?: number
+}
+
+export interface ThreadList {
+  pid: number
+  interval_ms: number
+  threads: ThreadInfo[]
+}
+
+export async function fetchProcessThreads(pid: number): Promise<ThreadList> {
+  const res = await fetch(`${BASE}/api/processes/${pid}/threads`)
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({})) as { error?: string }
+    throw new Error(err.error || res.statusText)
+  }
+  return res.json()
+}
+
+export interface StuckProcess {
+  pid: number
+  ppid: number
+  name: string
+  state: 'zombie' | 'blocked' | 'stop'
+  username?: string
+  cmdline?: string
+  parent_name?: string // только для зомби
+  wchan?: string
+  since: number
+  duration_sec: number
+  lower_bound?: boolean // состояние было уже при запуске Eye
+}
+
+export interface StuckReport {
+  zombie: number
+  blocked: number
+  stopped: number
+  time: number
+  processes: StuckProcess[]
+  zombie_parents: { pid: number; name?: string; zombies: number }[]
+}
+
+export async function fetchStuckProcesses(minSec = 0): Promise<StuckReport> {
+  const res = await fetch(`${BASE}/api/processes/stuck${minSec > 0 ? `?min_sec=${minSec}` : ''}`)
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({})) as { error?: string }
+    throw new Error(err.error || res.statusText)
+  }
+  return res.json()
+}
+
+export type OpenFileType = 'file' | 'dir' | 'socket' | 'pipe' | 'fifo' | 'char' | 'block' | 'anon' | 'other'
+
+export interface OpenFile {
+  fd: number
+  path: string
+  type: OpenFileType
+  mode?: 'r' | 'w' | 'rw'
+  deleted?: boolean
+}
+
+export async function fetchProcessFiles(pid: number): Promise<OpenFile[]> {
+  const res = await fetch(`${BASE}/api/processes/${pid}/files`)
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({})) as { error?: string }
+    throw new Error(err.error || res.statusText)
+  }
+  return res.json()
+}
+
+export interface FileUse {
+  use: 'fd' | 'cwd' | 'root' | 'exe' | 'mmap'
+  fd?: number
+  path: string
+  type?: OpenFileType
+  mode?: OpenFile['mode']
+  deleted?: boolean
+}
+
+export interface FileHolder {
+  pid: number
+  name: string
+  username?: string
+  uses: FileUse[]
+}
+
+export interface FileHolders {
+  path: string
+  holders: FileHolder[]
+  scanned: number
+  denied?: number
+}
+
+export async function fetchFileHolders(path: string): Promise<FileHolders> {
+  const res = await fetch(`${BASE}/api/files/holders?path=${encodeURIComponent(path)}`)
   if (!res.ok) {
     const err = await res.json().catch(() => ({})) as { error?: string }
     throw new Error(err.error || res.statusText)
   }
+  return res.json()
+}
+
+export interface RemoteHost {
+  addr: string
+  host?: string
+  connections: number
+  ports: number[]
+  states?: Record<string, number>
+  processes: { pid: number; name: string; connections: number }[]
+  loopback?: boolean
+  private?: boolean
+}
+
+export async function fetchRemoteHosts(params?: { resolve?: boolean; exclude_loopback?: boolean }): Promise<RemoteHost[]> {
+  const sp = new URLSearchParams()
+  if (params?.resolve) sp.set('resolve', '1')
+  if (params?.exclude_loopback) sp.set('exclude_loopback', '1')
+  const res = await fetch(`${BASE}/api/connections/hosts${sp.toString() ? `?${sp}` : ''}`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+export interface ProcessEvent {
+  id: number
+  type: 'start' | 'exit'
+  time: number
+  pid: number
+  ppid: number
+  parent_name?: string
+  name: string
+  cmdline?: string
+  username?: string
+  start_time?: number
+  lifetime_sec?: number
+  peak_cpu_percent?: number
+  peak_rss_mb?: number
+}
+
+export interface ProcessEventSummary {
+  name: string
+  starts: number
+  exits: number
+  peak_cpu_percent?: number
+  peak_rss_mb?: number
+  parents?: string[]
+  first_time: number
+  last_time: number
+}
+
+export interface ProcessEventsResponse {
+  events: ProcessEvent[]
+  last_id?: number
+  summary?: ProcessEventSummary[]
+}
+
+export async function fetchProcessEvents(params?: {
+  after?: number
+  since?: number
+  type?: 'start' | 'exit'
+  name?: string
+  limit?: number
+  summary?: boolean
+}): Promise<ProcessEventsResponse> {
+  const sp = new URLSearchParams()
+  if (params?.after != null) sp.set('after', String(params.after))
+  if (params?.since != null) sp.set('since', String(params.since))
+  if (params?.type) sp.set('type', params.type)
+  if (params?.name) sp.set('name', params.name)
+  if (params?.limit != null) sp.set('limit', String(params.limit))
+  if (params?.summary) sp.set('summary', '1')
+  const res = await fetch(`${BASE}/api/processes/events${sp.toString() ? `?${sp}` : ''}`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+export type WatchKind = 'pid' | 'exe' | 'name' | 'cmdline'
+
+export interface WatchEntry {
+  id: string
+  kind: WatchKind
+  pattern: string
+  label?: string
+  exe?: string
+  cmdline?: string
+  start_time?: number
+  created_at: number
+}
+
+export interface WatchSample {
+  time: number
+  pids?: number[]
+  cpu_percent: number
+  rss_mb: number
+  threads: number
+  fds: number
+  disk_read_bps: number
+  disk_write_bps: number
+}
+
+export interface WatchStatus extends WatchEntry {
+  running: boolean
+  restarts: number
+  peak_rss_mb: number
+  latest?: WatchSample
+}
+
+export async function fetchWatchlist(): Promise<WatchStatus[]> {
+  const res = await fetch(`${BASE}/api/watchlist`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+export async function addWatch(entry: { kind: WatchKind; pattern: string; label?: string }): Promise<WatchEntry> {
+  const res = await fetch(`${BASE}/api/watchlist`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(entry),
+  })
+  const data = await res.json().catch(() => ({}))
+  if (!res.ok) throw new Error(data.error || res.statusText)
+  return data
+}
+
+export async function updateWatch(id: string, patch: Partial<Pick<WatchEntry, 'kind' | 'pattern' | 'label'>>): Promise<WatchEntry> {
+  const res = await fetch(`${BASE}/api/watchlist/${encodeURIComponent(id)}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(patch),
+  })
+  const data = await res.json().catch(() => ({}))
+  if (!res.ok) throw new Error(data.error || res.statusText)
+  return data
+}
+
+export async function deleteWatch(id: string): Promise<void> {
+  const res = await fetch(`${BASE}/api/watchlist/${encodeURIComponent(id)}`, {
+    method: 'DELETE',
+    headers: { 'Content-Type': 'application/json' },
+  })
+  if (!res.ok) {
+    const data = await res.json().catch(() => ({}))
+    throw new Error(data.error || res.statusText)
+  }
+}
+
+export async function fetchWatchHistory(id: string, params?: { since?: number; points?: number }): Promise<WatchSample[]> {
+  const sp = new URLSearchParams()
+  if (params?.since != null) sp.set('since', String(params.since))
+  if (params?.points != null) sp.set('points', String(params.points))
+  const res = await fetch(`${BASE}/api/watchlist/${encodeURIComponent(id)}/history${sp.toString() ? `?${sp}` : ''}`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+export type RuleAction = 'notify' | 'renice' | 'suspend' | 'terminate'
+export type RuleMetric = 'cpu' | 'rss' | 'threads' | 'fds' | 'disk_read' | 'disk_write'
+
+export interface RuleCondition {
+  metric: RuleMetric
+  op: '>' | '<'
+  value: number
+}
+
+export interface Rule {
+  id: string
+  name?: string
+  enabled: boolean
+  match: { name?: string; cmdline?: string; user?: string }
+  conditions: RuleCondition[]
+  for_sec: number
+  action: RuleAction
+  nice?: number
+  grace_ms?: number
+  cooldown_sec?: number
+  dry_run?: boolean
+  confirmed?: boolean
+  created_at: number
+}
+
+export type RuleInput = Omit<Rule, 'id' | 'created_at'>
+
+export interface RuleEvidence extends RuleCondition {
+  current: number
+  min: number
+  max: number
+}
+
+export interface RuleFiring {
+  id: number
+  time: number
+  rule_id: string
+  rule_name: string
+  action: RuleAction
+  dry_run?: boolean
+  pid: number
+  name: string
+  cmdline?: string
+  username?: string
+  since: number
+  samples: number
+  evidence: RuleEvidence[]
+  result: string
+  error?: string
+}
+
+export interface RuleStatus extends Rule {
+  matching: number
+  pending?: { pid: number; name: string; since: number }[]
+  firings: number
+  last_firing?: RuleFiring
+}
+
+export interface RuleCandidate {
+  pid: number
+  name: string
+  values: Partial<Record<RuleMetric, number>>
+  holds: boolean
+  protection?: { pid: number; name?: string; decision: 'allow' | 'confirm' | 'deny'; reason?: string }
+}
+
+export async function fetchRules(): Promise<RuleStatus[]> {
+  const res = await fetch(`${BASE}/api/rules`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+async function postRule<T>(path: string, body: unknown): Promise<T> {
+  const res = await fetch(`${BASE}/api/rules${path}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  })
+  const data = await res.json().catch(() => ({}))
+  // Правило с confirmed для renice/suspend/terminate — 428: повторите с opts.confirmToken.
+  if (data.protection && res.status === 428) {
+    throw new ProtectionError(data.error || res.statusText, res.status, data.protection)
+  }
+  if (!res.ok) throw new Error(data.error || res.statusText)
+  return data
+}
+
+export const addRule = (rule: RuleInput, opts?: GuardOptions) =>
+  postRule<Rule>('', { ...rule, confirm_token: opts?.confirmToken })
+export const updateRule = (id: string, patch: Partial<RuleInput>, opts?: GuardOptions) =>
+  postRule<Rule>(`/${encodeURIComponent(id)}`, { ...patch, confirm_token: opts?.confirmToken })
+export const testRule = (rule: RuleInput) =>
+  postRule<{ candidates: RuleCandidate[] }>('/test', rule).then((data) => data.candidates)
+
+export async function deleteRule(id: string): Promise<void> {
+  const res = await fetch(`${BASE}/api/rules/${encodeURIComponent(id)}`, {
+    method: 'DELETE',
+    headers: { 'Content-Type': 'application/json' },
+  })
+  if (!res.ok) {
+    const data = await res.json().catch(() => ({}))
+    throw new Error(data.error || res.statusText)
+  }
+}
+
+export async function fetchRuleFirings(params?: { rule?: string; after?: number; since?: number; limit?: number }): Promise<RuleFiring[]> {
+  const sp = new URLSearchParams()
+  if (params?.rule) sp.set('rule', params.rule)
+  if (params?.after != null) sp.set('after', String(params.after))
+  if (params?.since != null) sp.set('since', String(params.since))
+  if (params?.limit != null) sp.set('limit', String(params.limit))
+  const res = await fetch(`${BASE}/api/rules/firings${sp.toString() ? `?${sp}` : ''}`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+export interface AuditEntry {
+  time: number
+  action: string
+  origin: 'http' | 'hub' | 'rule'
+  remote?: string
+  pid?: number
+  name?: string
+  cmdline?: string
+  details?: Record<string, string>
+  result: string
+  error?: string
+}
+
+export async function fetchAudit(params?: {
+  since?: number
+  until?: number
+  action?: string[]
+  origin?: 'http' | 'hub'
+  pid?: number
+  limit?: number
+}): Promise<AuditEntry[]> {
+  const sp = new URLSearchParams()
+  if (params?.since != null) sp.set('since', String(params.since))
+  if (params?.until != null) sp.set('until', String(params.until))
+  if (params?.action?.length) sp.set('action', params.action.join(','))
+  if (params?.origin) sp.set('origin', params.origin)
+  if (params?.pid != null) sp.set('pid', String(params.pid))
+  if (params?.limit != null) sp.set('limit', String(params.limit))
+  const res = await fetch(`${BASE}/api/audit${sp.toString() ? `?${sp}` : ''}`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+export interface SnapshotInfo {
+  name: string
+  time: number
+  hostname?: string
+  process_count: number
+  cpu_percent: number
+  memory_used_mb: number
+  memory_total_mb: number
+}
+
+export interface SnapshotProcess {
+  pid: number
+  name: string
+  exe?: string
+  cmdline?: string
+  username?: string
+  start_time?: number
+  cpu_percent: number
+  rss_mb: number
+  threads?: number
+  fds?: number
+  disk_read_bps?: number
+  disk_write_bps?: number
+}
+
+export interface Snapshot extends SnapshotInfo {
+  processes: SnapshotProcess[]
+}
+
+export interface SnapshotChange {
+  pid: number
+  prev_pid?: number // если процесс перезапущен
+  name: string
+  cmdline?: string
+  restarted?: boolean
+  cpu_before: number
+  cpu_after: number
+  cpu_delta: number
+  rss_before_mb: number
+  rss_after_mb: number
+  rss_delta_mb: number
+}
+
+export interface SnapshotDiff {
+  from: SnapshotInfo
+  to: SnapshotInfo
+  appeared: SnapshotProcess[]
+  disappeared: SnapshotProcess[]
+  changed: SnapshotChange[]
+  unchanged: number
+  rss_delta_mb: number
+}
+
+export async function fetchSnapshots(): Promise<SnapshotInfo[]> {
+  const res = await fetch(`${BASE}/api/snapshots`)
+  if (!res.ok) throw new Error(res.statusText)
+  return res.json()
+}
+
+export async function fetchSnapshot(name: string): Promise<Snapshot> {
+  const res = await fetch(`${BASE}/api/snapshots/${encodeURIComponent(name)}`)
+  const data = await res.json().catch(() => ({}))
+  if (!res.ok) throw new Error(data.error || res.statusText)
+  return data
+}
+
+// captureSnapshot снимает таблицу процессов; без имени — по времени снимка.
+export async function captureSnapshot(name?: string): Promise<SnapshotInfo> {
+  const res = await fetch(`${BASE}/api/snapshots`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ name: name ?? '' }),
+  })
+  const data = await res.json().catch(() => ({}))
+  if (!res.ok) throw new Error(data.error || res.statusText)
+  return data
+}
+
+export async function deleteSnapshot(name: string): Promise<void> {
+  const res = await fetch(`${BASE}/api/snapshots/${encodeURIComponent(name)}`, {
+    method: 'DELETE',
+    headers: { 'Content-Type': 'application/json' },
+  })
+  if (!res.ok) {
+    const data = await res.json().catch(() => ({}))
+    throw new Error(data.error || res.statusText)
+  }
+}
+
+// fetchSnapshotDiff сравнивает снимок from с to ('now' — текущая таблица процессов).
+export async function fetchSnapshotDiff(
+  from: string,
+  params?: { to?: string; min_cpu?: number; min_rss_mb?: number },
+): Promise<SnapshotDiff> {
+  const sp = new URLSearchParams()
+  if (params?.to) sp.set('to', params.to)
+  if (params?.min_cpu != null) sp.set('min_cpu', String(params.min_cpu))
+  if (params?.min_rss_mb != null) sp.set('min_rss_mb', String(params.min_rss_mb))
+  const res = await fetch(`${BASE}/api/snapshots/${encodeURIComponent(from)}/diff${sp.toString() ? `?${sp}` : ''}`)
+  const data = await res.json().catch(() => ({}))
+  if (!res.ok) throw new Error(data.error || res.statusText)
+  return data
 }
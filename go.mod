@@ -5,6 +5,7 @@ go 1.26
 require (
 	github.com/GalitskyKK/nekkus-core v0.2.0
 	github.com/shirou/gopsutil/v3 v3.24.5
+	golang.org/x/sys v0.40.0
 	google.golang.org/grpc v1.78.0
 )
 
@@ -31,7 +32,6 @@ require (
 	github.com/webview/webview_go v0.0.0-20240831120633-6173450d4dd6 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
 	golang.org/x/net v0.49.0 // indirect
-	golang.org/x/sys v0.40.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251029180050-ab9386a59fda // indirect
 	google.golang.org/protobuf v1.36.10 // indirect
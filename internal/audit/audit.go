@@ -0,0 +1,265 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+)
+
+const (
+	fileName = "audit.log"
+	// Текущий файл переименовывается в audit.log.1 при превышении maxSize; хранится backups старых файлов.
+	maxSize = 5 << 20
+	backups = 3
+)
+
+// Источники действий (Entry.Origin).
+const (
+	OriginHTTP = "http" // HTTP API (UI или внешние клиенты)
+	OriginHub  = "hub"  // Execute через gRPC от Hub
+	OriginRule = "rule" // автоматическое правило (Details["rule"] — его ID)
+)
+
+// Результаты, которые ставит защита процессов (остальные — Outcome* из monitor, "ok" или "error").
+const (
+	ResultOK                   = "ok"
+	ResultError                = "error"
+	ResultDenied               = "denied"
+	ResultConfirmationRequired = "confirmation_required"
+)
+
+// Entry — запись журнала: одно изменяющее действие и его результат.
+type Entry struct {
+	Time    int64             `json:"time"`   // unix, мс
+	Action  string            `json:"action"` // kill, kill-tree, terminate, suspend, resume, signal, priority, settings, watchlist.*, rules.*, snapshots.*
+	Origin  string            `json:"origin"`
+	Remote  string            `json:"remote,omitempty"` // адрес клиента
+	PID     int32             `json:"pid,omitempty"`
+	Name    string            `json:"name,omitempty"`
+	Cmdline string            `json:"cmdline,omitempty"`
+	Details map[string]string `json:"details,omitempty"` // параметры действия
+	Result  string            `json:"result"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// Target — запись о действии action над pid. Процесс читается до действия: после kill его уже нет.
+func Target(action string, pid int32) Entry {
+	e := Entry{Action: action, PID: pid}
+	if id, ok := monitor.LookupProcess(pid); ok {
+		e.Name, e.Cmdline = id.Name, id.Cmdline
+	}
+	return e
+}
+
+// Filter — выборка из журнала.
+type Filter struct {
+	Since, Until time.Time
+	Actions      []string // пусто — все
+	Origin       string
+	PID          int32
+	Limit        int // последние Limit записей; 0 — все
+}
+
+// Log — журнал аудита в data-dir/audit.log: JSON по строке на запись, только дописывается.
+// Методы nil-журнала ничего не делают, чтобы ошибка открытия не ломала действия.
+type Log struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64
+	// rotations растёт при каждой ротации: Query читает файлы без блокировки и по нему
+	// узнаёт, что файлы сдвинулись во время чтения.
+	rotations int
+}
+
+// Open открывает (или создаёт) журнал в dataDir.
+func Open(dataDir string) (*Log, error) {
+	l := &Log{path: filepath.Join(dataDir, fileName)}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) open() error {
+	// Журнал содержит командные строки процессов — читать его может только владелец.
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size = f, st.Size()
+	return nil
+}
+
+// Record дописывает запись; Time заполняется, если не задано.
+func (l *Log) Record(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	if e.Time == 0 {
+		e.Time = time.Now().UnixMilli()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Если ротация не удалась, запись всё равно дописывается в текущий файл;
+	// следующая запись попробует ротацию снова.
+	var rotateErr error
+	if l.f != nil && l.size+int64(len(b)) > maxSize && l.size > 0 {
+		rotateErr = l.rotate()
+	}
+	if l.f == nil {
+		// Файл не открылся после ротации — каждая запись пробует открыть его снова.
+		if err := l.open(); err != nil {
+			return errors.Join(rotateErr, err)
+		}
+	}
+	n, err := l.f.Write(b)
+	l.size += int64(n)
+	if err == nil {
+		err = l.f.Sync()
+	}
+	return errors.Join(rotateErr, err)
+}
+
+// rotate сдвигает audit.log.N → N+1 (самый старый удаляется) и начинает новый файл.
+// Файл закрывается до переименования (в Windows открытый не переименовать) и при любом
+// исходе открывается снова; если не открылся, l.f остаётся nil.
+func (l *Log) rotate() error {
+	l.f.Close()
+	l.f = nil
+	l.rotations++
+	var err error
+	for i := backups; i >= 1 && err == nil; i-- {
+		src := l.path
+		if i > 1 {
+			src = fmt.Sprintf("%s.%d", l.path, i-1)
+		}
+		if err = os.Rename(src, fmt.Sprintf("%s.%d", l.path, i)); os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	return errors.Join(err, l.open())
+}
+
+// Query возвращает записи, подходящие под f, от старых к новым (включая ротированные файлы).
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	out := make([]Entry, 0)
+	if l == nil {
+		return out, nil
+	}
+	// Файлы читаются без блокировки, чтобы Record не ждал; текущий — до размера на момент
+	// начала. Если за это время прошла ротация, файлы сдвинулись — читаем заново.
+	for {
+		l.mu.Lock()
+		rotations, size := l.rotations, l.size
+		l.mu.Unlock()
+		out = out[:0]
+		for i := backups; i >= 0; i-- {
+			path, limit := l.path, size
+			if i > 0 {
+				path, limit = fmt.Sprintf("%s.%d", l.path, i), -1
+			}
+			if err := scanFile(path, limit, func(e Entry) {
+				if f.match(e) {
+					out = append(out, e)
+				}
+			}); err != nil {
+				return nil, err
+			}
+		}
+		l.mu.Lock()
+		rotated := l.rotations != rotations
+		l.mu.Unlock()
+		if !rotated {
+			break
+		}
+	}
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[len(out)-f.Limit:]
+	}
+	return out, nil
+}
+
+// scanFile передаёт в fn записи файла path; limit >= 0 — только первые limit байт.
+func scanFile(path string, limit int64, fn func(Entry)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if limit >= 0 {
+		r = io.LimitReader(file, limit)
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var e Entry
+		// Обрезанную последнюю строку (сбой при записи) пропускаем.
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			fn(e)
+		}
+	}
+	return sc.Err()
+}
+
+func (f Filter) match(e Entry) bool {
+	switch {
+	case !f.Since.IsZero() && e.Time < f.Since.UnixMilli():
+		return false
+	case !f.Until.IsZero() && e.Time > f.Until.UnixMilli():
+		return false
+	case f.Origin != "" && e.Origin != f.Origin:
+		return false
+	case f.PID != 0 && e.PID != f.PID:
+		return false
+	}
+	if len(f.Actions) == 0 {
+		return true
+	}
+	for _, a := range f.Actions {
+		// "watchlist" подходит ко всем watchlist.*.
+		if a == e.Action || strings.HasPrefix(e.Action, a+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Close закрывает файл журнала.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// big — запись ~600 КБ: ротация наступает через несколько записей.
+func big(pid int32) Entry {
+	return Entry{Action: "kill", PID: pid, Cmdline: strings.Repeat("x", 600<<10), Result: ResultOK}
+}
+
+func pids(entries []Entry) []int32 {
+	out := make([]int32, len(entries))
+	for i, e := range entries {
+		out[i] = e.PID
+	}
+	return out
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for pid := int32(1); pid <= 10; pid++ {
+		if err := l.Record(big(pid)); err != nil {
+			t.Fatalf("record %d: %v", pid, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, fileName+".1")); err != nil {
+		t.Fatalf("no rotated file: %v", err)
+	}
+	got, err := l.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 10 || got[0].PID != 1 || got[9].PID != 10 {
+		t.Fatalf("query after rotation: pids %v", pids(got))
+	}
+}
+
+func TestRotateFailureKeepsLogging(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	// audit.log.2 → audit.log.3 не переименовать: .3 — непустой каталог.
+	if err := os.MkdirAll(filepath.Join(dir, fileName+".3", "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fileName+".2"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var rotateErr error
+	for pid := int32(1); pid <= 10; pid++ {
+		if err := l.Record(big(pid)); err != nil && rotateErr == nil {
+			rotateErr = err
+		}
+	}
+	if rotateErr == nil {
+		t.Fatal("rotation did not fail")
+	}
+	if err := l.Record(Entry{Action: "kill", PID: 11, Result: ResultOK}); err == nil {
+		t.Fatal("rotation is not retried")
+	}
+	if err := os.RemoveAll(filepath.Join(dir, fileName+".3")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := l.Query(Filter{PID: 11})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("entry after failed rotation: %v, %v", pids(got), err)
+	}
+	if got, _ := l.Query(Filter{}); len(got) != 11 {
+		t.Fatalf("entries after failed rotation: %v", pids(got))
+	}
+}
@@ -4,26 +4,39 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	pb "github.com/GalitskyKK/nekkus-core/pkg/protocol"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
 	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/rules"
+	"github.com/GalitskyKK/nekkus-eye/internal/settings"
+	"github.com/GalitskyKK/nekkus-eye/internal/watchlist"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
 )
 
 // EyeModule реализует NekkusModule для System Monitor.
 type EyeModule struct {
 	pb.UnimplementedNekkusModuleServer
 	collector *monitor.Collector
+	store     *settings.Store
+	watch     *watchlist.Watchlist
+	guard     *monitor.ProcessGuard
+	audit     *audit.Log
+	rules     *rules.Engine
 	httpPort  int
 }
 
 // New создаёт EyeModule.
-func New(collector *monitor.Collector, httpPort int) *EyeModule {
+func New(collector *monitor.Collector, httpPort int, store *settings.Store, watch *watchlist.Watchlist, guard *monitor.ProcessGuard, alog *audit.Log, rl *rules.Engine) *EyeModule {
 	if httpPort <= 0 {
 		httpPort = 9002
 	}
-	return &EyeModule{collector: collector, httpPort: httpPort}
+	return &EyeModule{collector: collector, store: store, watch: watch, guard: guard, audit: alog, rules: rl, httpPort: httpPort}
 }
 
 func (m *EyeModule) GetInfo(ctx context.Context, _ *pb.Empty) (*pb.ModuleInfo, error) {
@@ -36,7 +49,7 @@ func (m *EyeModule) GetInfo(ctx context.Context, _ *pb.Empty) (*pb.ModuleInfo, e
 		HttpPort:     int32(m.httpPort),
 		GrpcPort:     19002,
 		UiUrl:        fmt.Sprintf("http://127.0.0.1:%d", m.httpPort),
-		Capabilities: []string{"monitor.cpu", "monitor.memory", "monitor.stats"},
+		Capabilities: []string{"monitor.cpu", "monitor.memory", "monitor.stats", "monitor.gpu"},
 		Provides:     []string{"monitor.stats", "monitor.cpu", "monitor.memory"},
 		Status:       pb.ModuleStatus_MODULE_RUNNING,
 	}, nil
@@ -48,14 +61,17 @@ func (m *EyeModule) Health(ctx context.Context, _ *pb.Empty) (*pb.HealthStatus,
 		Healthy: true,
 		Message: "ok",
 		Details: map[string]string{
-			"cpu_percent":    fmt.Sprintf("%.1f", s.CPUPercent),
-			"memory_percent": fmt.Sprintf("%.1f", s.MemoryPercent),
+			"cpu_percent":       fmt.Sprintf("%.1f", s.CPUPercent),
+			"memory_percent":    fmt.Sprintf("%.1f", s.MemoryPercent),
+			"zombie_processes":  strconv.Itoa(s.ZombieCount),
+			"blocked_processes": strconv.Itoa(s.BlockedCount),
+			"stopped_processes": strconv.Itoa(s.StoppedCount),
 		},
 	}, nil
 }
 
 func (m *EyeModule) GetWidgets(ctx context.Context, _ *pb.Empty) (*pb.WidgetList, error) {
-	return &pb.WidgetList{
+	list := &pb.WidgetList{
 		Widgets: []*pb.Widget{
 			{
 				Id:                "eye.stats",
@@ -79,7 +95,28 @@ func (m *EyeModule) GetWidgets(ctx context.Context, _ *pb.Empty) (*pb.WidgetList
 				RefreshIntervalMs: 1000,
 			},
 		},
-	}, nil
+	}
+	// По виджету на каждый GPU — данные из /api/gpus/{index}.
+	for _, g := range m.collector.Get().GPUs {
+		list.Widgets = append(list.Widgets, &pb.Widget{
+			Id:                fmt.Sprintf("eye.gpu.%d", g.Index),
+			Title:             fmt.Sprintf("GPU %d: %s", g.Index, g.Name),
+			Size:              pb.WidgetSize_WIDGET_SMALL,
+			DataEndpoint:      fmt.Sprintf("/api/gpus/%d", g.Index),
+			RefreshIntervalMs: 2000,
+		})
+	}
+	// По виджету на каждую запись списка наблюдения — данные из /api/watchlist/{id}.
+	for _, e := range m.watch.List() {
+		list.Widgets = append(list.Widgets, &pb.Widget{
+			Id:                "eye.watch." + e.ID,
+			Title:             "Watch: " + e.Title(),
+			Size:              pb.WidgetSize_WIDGET_SMALL,
+			DataEndpoint:      "/api/watchlist/" + e.ID,
+			RefreshIntervalMs: 5000,
+		})
+	}
+	return list, nil
 }
 
 func (m *EyeModule) GetActions(ctx context.Context, _ *pb.Empty) (*pb.ActionList, error) {
@@ -93,6 +130,85 @@ func (m *EyeModule) GetActions(ctx context.Context, _ *pb.Empty) (*pb.ActionList
 				ModuleId:    "eye",
 				Tags:        []string{"monitor", "refresh"},
 			},
+			{
+				Id:          "eye.process.terminate",
+				Label:       "Terminate process",
+				Description: "SIGTERM, then SIGKILL after the grace period",
+				Icon:        "⏹",
+				ModuleId:    "eye",
+				Tags:        []string{"process", "kill"},
+				Params: []*pb.ActionParam{
+					pidParam,
+					{Name: "grace_ms", Type: "number", Label: "Grace period, ms", DefaultValue: strconv.Itoa(m.store.Get().TerminateGraceMs)},
+					dryRunParam,
+					confirmParam,
+				},
+			},
+			{
+				Id:          "eye.process.restart",
+				Label:       "Restart process",
+				Description: "Terminate the process and start it again with the same command line, directory and environment",
+				Icon:        "🔁",
+				ModuleId:    "eye",
+				Tags:        []string{"process", "restart"},
+				Params: []*pb.ActionParam{
+					pidParam,
+					{Name: "grace_ms", Type: "number", Label: "Grace period, ms", DefaultValue: strconv.Itoa(m.store.Get().TerminateGraceMs)},
+					{Name: "inherit", Type: "boolean", Label: "Use Eye's directory and environment if the process's cannot be read", DefaultValue: "false"},
+					dryRunParam,
+					confirmParam,
+				},
+			},
+			{
+				Id:          "eye.process.suspend",
+				Label:       "Suspend process",
+				Description: "Pause the process (SIGSTOP)",
+				Icon:        "⏸",
+				ModuleId:    "eye",
+				Tags:        []string{"process"},
+				Params:      []*pb.ActionParam{pidParam, dryRunParam, confirmParam},
+			},
+			{
+				Id:          "eye.process.resume",
+				Label:       "Resume process",
+				Description: "Continue a suspended process (SIGCONT)",
+				Icon:        "▶",
+				ModuleId:    "eye",
+				Tags:        []string{"process"},
+				Params:      []*pb.ActionParam{pidParam},
+			},
+			{
+				Id:          "eye.process.signal",
+				Label:       "Send signal",
+				Description: "Send a named signal to the process",
+				Icon:        "📨",
+				ModuleId:    "eye",
+				Tags:        []string{"process"},
+				Params: []*pb.ActionParam{
+					pidParam,
+					{Name: "signal", Type: "string", Label: "Signal", Required: true, DefaultValue: "SIGTERM", Options: signalOptions},
+					dryRunParam,
+					confirmParam,
+				},
+			},
+			{
+				Id:          "eye.process.priority",
+				Label:       "Set priority",
+				Description: "Change nice, CPU affinity and I/O priority of a process or its whole tree",
+				Icon:        "🐢",
+				ModuleId:    "eye",
+				Tags:        []string{"process", "priority"},
+				Params: []*pb.ActionParam{
+					pidParam,
+					{Name: "nice", Type: "number", Label: "Nice (-20..19)"},
+					{Name: "affinity", Type: "string", Label: "CPUs (e.g. 0-3 or 0xf)"},
+					{Name: "io_class", Type: "string", Label: "I/O class", Options: []string{"", monitor.IOClassNone, monitor.IOClassRealtime, monitor.IOClassBestEffort, monitor.IOClassIdle}},
+					{Name: "io_level", Type: "number", Label: "I/O level (0..7)"},
+					{Name: "tree", Type: "boolean", Label: "Apply to child processes", DefaultValue: "false"},
+					dryRunParam,
+					confirmParam,
+				},
+			},
 			{
 				Id:          "disconnect",
 				Label:       "Stop module",
@@ -105,12 +221,72 @@ func (m *EyeModule) GetActions(ctx context.Context, _ *pb.Empty) (*pb.ActionList
 	}, nil
 }
 
-func (m *EyeModule) StreamData(req *pb.StreamRequest, _ grpc.ServerStreamingServer[pb.DataEvent]) error {
-	return nil
+// Топики StreamData: события запуска/завершения процессов (payload — JSON-массив ProcessEvent)
+// и срабатывания правил (payload — JSON Firing).
+const (
+	processEventsTopic = "eye.process.events"
+	ruleFiringsTopic   = "eye.rules.firings"
+)
+
+func (m *EyeModule) StreamData(req *pb.StreamRequest, stream grpc.ServerStreamingServer[pb.DataEvent]) error {
+	wantEvents := slices.Contains(req.Topics, processEventsTopic)
+	wantFirings := slices.Contains(req.Topics, ruleFiringsTopic)
+	if !wantEvents && !wantFirings {
+		return nil
+	}
+	// nil-каналы не срабатывают в select — неподписанный топик просто молчит.
+	var events <-chan []monitor.ProcessEvent
+	if wantEvents {
+		ch, cancel := m.collector.SubscribeProcessEvents()
+		defer cancel()
+		events = ch
+	}
+	var firings <-chan rules.Firing
+	if wantFirings {
+		ch, cancel := m.rules.Subscribe()
+		defer cancel()
+		firings = ch
+	}
+	send := func(topic string, v interface{}) error {
+		payload, _ := json.Marshal(v)
+		return stream.Send(&pb.DataEvent{
+			Topic:     topic,
+			ModuleId:  "eye",
+			Timestamp: time.Now().Unix(),
+			Payload:   payload,
+		})
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case batch := <-events:
+			if err := send(processEventsTopic, batch); err != nil {
+				return err
+			}
+		case f := <-firings:
+			if err := send(ruleFiringsTopic, f); err != nil {
+				return err
+			}
+		}
+	}
 }
 
+var (
+	pidParam      = &pb.ActionParam{Name: "pid", Type: "number", Label: "PID", Required: true}
+	dryRunParam   = &pb.ActionParam{Name: "dry_run", Type: "boolean", Label: "Only check protection", DefaultValue: "false"}
+	confirmParam  = &pb.ActionParam{Name: "confirm_token", Type: "string", Label: "Confirmation token (for protected processes)"}
+	signalOptions = []string{"SIGTERM", "SIGKILL", "SIGINT", "SIGHUP", "SIGQUIT", "SIGUSR1", "SIGUSR2", "SIGSTOP", "SIGCONT"}
+)
+
 func (m *EyeModule) Execute(ctx context.Context, req *pb.ExecuteRequest) (*pb.ExecuteResponse, error) {
 	switch req.ActionId {
+	case "eye.process.terminate", "eye.process.suspend", "eye.process.resume", "eye.process.signal":
+		return m.executeSignal(ctx, req), nil
+	case "eye.process.restart":
+		return m.executeRestart(ctx, req), nil
+	case "eye.process.priority":
+		return m.executePriority(ctx, req), nil
 	case "disconnect":
 		return &pb.ExecuteResponse{Success: true, Message: "Stopped"}, nil
 	case "eye.refresh":
@@ -120,12 +296,238 @@ func (m *EyeModule) Execute(ctx context.Context, req *pb.ExecuteRequest) (*pb.Ex
 	return &pb.ExecuteResponse{Success: false, Error: "unknown action"}, nil
 }
 
+// executeSignal выполняет действия eye.process.* с параметрами pid, grace_ms, signal.
+func (m *EyeModule) executeSignal(ctx context.Context, req *pb.ExecuteRequest) *pb.ExecuteResponse {
+	pid, err := strconv.ParseInt(req.Params["pid"], 10, 32)
+	if err != nil || pid <= 0 {
+		return &pb.ExecuteResponse{Success: false, Error: "invalid pid"}
+	}
+	target := audit.Target(strings.TrimPrefix(req.ActionId, "eye.process."), int32(pid))
+	if req.ActionId == "eye.process.signal" {
+		target.Details = map[string]string{"signal": req.Params["signal"]}
+	}
+	// Защита процессов — как у HTTP API; resume ничего не ломает и не проверяется.
+	if req.ActionId != "eye.process.resume" {
+		if resp := m.authorize(ctx, req, target, []int32{target.PID}); resp != nil {
+			return resp
+		}
+	}
+
+	var res monitor.SignalResult
+	switch req.ActionId {
+	case "eye.process.terminate":
+		grace := m.store.Get().TerminateGrace()
+		if ms, err := strconv.Atoi(req.Params["grace_ms"]); err == nil && ms > 0 {
+			grace = time.Duration(ms) * time.Millisecond
+		}
+		res = monitor.TerminateProcess(ctx, int32(pid), grace, nil)
+	case "eye.process.suspend":
+		res = monitor.SuspendProcess(int32(pid))
+	case "eye.process.resume":
+		res = monitor.ResumeProcess(int32(pid))
+	default:
+		res = monitor.SignalProcess(int32(pid), req.Params["signal"])
+	}
+
+	target.Result, target.Error = res.Outcome, res.Error
+	if res.Escalated {
+		target.Details = map[string]string{"escalated": "true"}
+	}
+	m.record(ctx, target)
+
+	msg := fmt.Sprintf("PID %d: %s", res.PID, res.Outcome)
+	if res.Escalated {
+		msg += " (SIGKILL)"
+	}
+	resp := &pb.ExecuteResponse{Success: res.OK(), Message: msg}
+	if !res.OK() {
+		resp.Error = res.Error
+		if resp.Error == "" {
+			resp.Error = res.Outcome
+		}
+	}
+	return resp
+}
+
+// executeRestart выполняет eye.process.restart с параметрами pid, grace_ms и inherit.
+func (m *EyeModule) executeRestart(ctx context.Context, req *pb.ExecuteRequest) *pb.ExecuteResponse {
+	pid, err := strconv.ParseInt(req.Params["pid"], 10, 32)
+	if err != nil || pid <= 0 {
+		return &pb.ExecuteResponse{Success: false, Error: "invalid pid"}
+	}
+	target := audit.Target("restart", int32(pid))
+	if resp := m.authorize(ctx, req, target, []int32{target.PID}); resp != nil {
+		return resp
+	}
+	grace := m.store.Get().TerminateGrace()
+	if ms, err := strconv.Atoi(req.Params["grace_ms"]); err == nil && ms > 0 {
+		grace = time.Duration(ms) * time.Millisecond
+	}
+	inherit, _ := strconv.ParseBool(req.Params["inherit"])
+	res := monitor.RestartProcess(ctx, int32(pid), grace, inherit)
+	target.Result, target.Error = res.Outcome, res.Error
+	if res.NewPID > 0 {
+		target.Details = map[string]string{"new_pid": strconv.Itoa(int(res.NewPID))}
+	}
+	m.record(ctx, target)
+	if !res.OK() {
+		return &pb.ExecuteResponse{Success: false, Error: fmt.Sprintf("PID %d: %s: %s", pid, res.Outcome, res.Error)}
+	}
+	return &pb.ExecuteResponse{Success: true, Message: fmt.Sprintf("PID %d restarted as PID %d", pid, res.NewPID)}
+}
+
+// authorize проверяет действие target.Action над pids защитой процессов
+// (параметры dry_run и confirm_token). Возвращает ответ, если выполнять не нужно;
+// отклонённые попытки записывает в аудит.
+func (m *EyeModule) authorize(ctx context.Context, req *pb.ExecuteRequest, target audit.Entry, pids []int32) *pb.ExecuteResponse {
+	dryRun, _ := strconv.ParseBool(req.Params["dry_run"])
+	auth := m.guard.Authorize(target.Action, pids, req.Params["confirm_token"], dryRun)
+	resp := authorizationResponse(auth, dryRun)
+	switch {
+	case resp == nil || dryRun:
+	case auth.Denied():
+		target.Result = audit.ResultDenied
+		m.record(ctx, target)
+	default:
+		target.Result = audit.ResultConfirmationRequired
+		m.record(ctx, target)
+	}
+	return resp
+}
+
+// authorizationResponse — ответ Execute, если действие не выполняется: запрещено, ждёт
+// подтверждения (токен — в сообщении) или это dry-run. nil — можно выполнять.
+func authorizationResponse(auth monitor.Authorization, dryRun bool) *pb.ExecuteResponse {
+	var blocked *monitor.ProtectionCheck
+	for i, c := range auth.Checks {
+		if c.Decision != monitor.ProtectionAllow {
+			blocked = &auth.Checks[i]
+			break
+		}
+	}
+	describe := func() string {
+		if blocked == nil {
+			return ""
+		}
+		return fmt.Sprintf("PID %d (%s): %s", blocked.PID, blocked.Name, blocked.Reason)
+	}
+	switch {
+	case auth.Denied():
+		return &pb.ExecuteResponse{Success: false, Error: "process is protected: " + describe()}
+	case dryRun:
+		msg := "allowed"
+		if auth.ConfirmToken != "" {
+			msg = "needs confirmation: " + describe() + "; confirm_token=" + auth.ConfirmToken
+		}
+		return &pb.ExecuteResponse{Success: true, Message: "dry run: " + msg}
+	case auth.NeedsConfirmation():
+		return &pb.ExecuteResponse{
+			Success: false,
+			Message: "confirm_token=" + auth.ConfirmToken,
+			Error:   fmt.Sprintf("confirmation required: %s; repeat with confirm_token within %ds", describe(), auth.ConfirmExpiresInMs/1000),
+		}
+	}
+	return nil
+}
+
+// record дописывает запись аудита с источником Hub (адрес — gRPC-клиент из ctx).
+func (m *EyeModule) record(ctx context.Context, e audit.Entry) {
+	e.Origin = audit.OriginHub
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		e.Remote = p.Addr.String()
+	}
+	_ = m.audit.Record(e)
+}
+
+// executePriority выполняет eye.process.priority; пустые параметры не меняются.
+// Защита проверяет процесс (с tree — всё дерево), как для terminate.
+func (m *EyeModule) executePriority(ctx context.Context, req *pb.ExecuteRequest) *pb.ExecuteResponse {
+	pid, err := strconv.ParseInt(req.Params["pid"], 10, 32)
+	if err != nil || pid <= 0 {
+		return &pb.ExecuteResponse{Success: false, Error: "invalid pid"}
+	}
+	ch, err := priorityChange(req)
+	if err != nil {
+		return &pb.ExecuteResponse{Success: false, Error: err.Error()}
+	}
+	pids := []int32{int32(pid)}
+	if tree, _ := strconv.ParseBool(req.Params["tree"]); tree {
+		if pids, err = monitor.ProcessTreePIDs(int32(pid)); err != nil {
+			return &pb.ExecuteResponse{Success: false, Error: err.Error()}
+		}
+	}
+	target := audit.Target("priority", int32(pid))
+	target.Details = map[string]string{}
+	for k, v := range req.Params {
+		if k != "pid" && k != "dry_run" && k != "confirm_token" && v != "" {
+			target.Details[k] = v
+		}
+	}
+	if resp := m.authorize(ctx, req, target, pids); resp != nil {
+		return resp
+	}
+	resp := runPriority(ch, pids)
+	target.Result = audit.ResultOK
+	if !resp.Success {
+		target.Result, target.Error = audit.ResultError, resp.Error
+	}
+	m.record(ctx, target)
+	return resp
+}
+
+// priorityChange разбирает параметры eye.process.priority.
+func priorityChange(req *pb.ExecuteRequest) (monitor.PriorityChange, error) {
+	ch := monitor.PriorityChange{Affinity: req.Params["affinity"], IOClass: req.Params["io_class"]}
+	for name, dst := range map[string]**int{"nice": &ch.Nice, "io_level": &ch.IOLevel} {
+		v := req.Params[name]
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return ch, fmt.Errorf("invalid %s", name)
+		}
+		*dst = &n
+	}
+	return ch, ch.Validate()
+}
+
+// runPriority применяет ch к pids (корень — последний, как у ProcessTreePIDs).
+func runPriority(ch monitor.PriorityChange, pids []int32) *pb.ExecuteResponse {
+	results := monitor.SetProcessListPriority(pids, ch)
+	failed := 0
+	firstErr := ""
+	for _, r := range results {
+		if !r.OK() {
+			failed++
+			if firstErr == "" {
+				firstErr = fmt.Sprintf("PID %d: %s", r.PID, r.Error)
+			}
+		}
+	}
+	root := results[0]
+	msg := fmt.Sprintf("PID %d: %s", root.PID, root.Outcome)
+	if p := root.Priority; p != nil {
+		msg = fmt.Sprintf("PID %d: nice %d, cpus %s", root.PID, p.Nice, p.Affinity)
+		if io := p.IOPriority(); io != "" {
+			msg += ", io " + io
+		}
+	}
+	if len(results) > 1 {
+		msg += fmt.Sprintf(" (%d/%d processes updated)", len(results)-failed, len(results))
+	}
+	return &pb.ExecuteResponse{Success: failed == 0, Message: msg, Error: firstErr}
+}
+
 func (m *EyeModule) Query(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
 	switch req.QueryType {
 	case "stats":
 		s := m.collector.Get()
 		data, _ := json.Marshal(s)
 		return &pb.QueryResponse{Success: true, Data: data}, nil
+	case "gpus":
+		data, _ := json.Marshal(m.collector.Get().GPUs)
+		return &pb.QueryResponse{Success: true, Data: data}, nil
 	}
 	return &pb.QueryResponse{Success: false, Error: "unknown query"}, nil
 }
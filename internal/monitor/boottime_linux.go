@@ -0,0 +1,50 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// createTimeSkewMs — поправка к CreateTime из gopsutil. Он считает старт процесса в целых
+// секундах: btime из /proc/stat плюс starttime, делённый на тики нацело. Поправка возвращает
+// дробную часть btime (точное время загрузки — now минус /proc/uptime), но секундное
+// округление starttime остаётся: время старта и жизни процесса точно только до секунды.
+// Поправка считается один раз при запуске: после перевода часов btime сдвигается
+// (см. createTimeTolerance).
+func createTimeSkewMs() int64 {
+	b, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return 0
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	precise := time.Now().UnixMilli() - int64(uptime*1000)
+
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if v, ok := strings.CutPrefix(sc.Text(), "btime "); ok {
+			btime, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return 0
+			}
+			return precise - btime*1000
+		}
+	}
+	return 0
+}
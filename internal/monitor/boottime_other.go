@@ -0,0 +1,5 @@
+//go:build !linux
+
+package monitor
+
+func createTimeSkewMs() int64 { return 0 }
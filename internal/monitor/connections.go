@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"net/netip"
+	"sort"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// RemoteHost — удалённый адрес и все соединения с ним по всем процессам.
+type RemoteHost struct {
+	Addr        string              `json:"addr"`
+	Host        string              `json:"host,omitempty"` // обратный DNS, если запрошен и уже известен
+	Connections int                 `json:"connections"`
+	Ports       []uint32            `json:"ports"`
+	States      map[string]int      `json:"states,omitempty"`
+	Processes   []RemoteHostProcess `json:"processes"`
+	Loopback    bool                `json:"loopback,omitempty"`
+	Private     bool                `json:"private,omitempty"`
+}
+
+// RemoteHostProcess — процесс, держащий соединения с RemoteHost.
+type RemoteHostProcess struct {
+	PID         int32  `json:"pid"`
+	Name        string `json:"name"`
+	Connections int    `json:"connections"`
+}
+
+// ProcessConnections возвращает сокеты процесса pid. С resolve удалённые адреса
+// дополняются именами из кэша обратного DNS (неизвестные резолвятся в фоне).
+func (c *Collector) ProcessConnections(pid int32, resolve bool) ([]ProcessConnection, error) {
+	if ok, _ := process.PidExists(pid); !ok {
+		return nil, ErrProcessNotFound
+	}
+	conns, err := net.ConnectionsPid("all", pid)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ProcessConnection, 0, len(conns))
+	for _, conn := range conns {
+		pc := toProcessConnection(conn)
+		if resolve {
+			pc.RemoteHost = c.dns.lookup(pc.RemoteAddr)
+		}
+		out = append(out, pc)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Protocol != out[j].Protocol {
+			return out[i].Protocol < out[j].Protocol
+		}
+		return out[i].LocalPort < out[j].LocalPort
+	})
+	return out, nil
+}
+
+// RemoteHosts собирает удалённые адреса всех TCP/UDP-соединений системы,
+// по убыванию числа соединений. Слушающие сокеты без удалённой стороны пропускаются.
+func (c *Collector) RemoteHosts(resolve bool) ([]RemoteHost, error) {
+	conns, err := net.Connections("inet")
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int32]string)
+	for _, s := range c.processSnapshots() {
+		names[s.info.PID] = s.info.Name
+	}
+
+	hosts := make(map[string]*RemoteHost)
+	procs := make(map[string]map[int32]int)
+	for _, conn := range conns {
+		addr, err := netip.ParseAddr(conn.Raddr.IP)
+		if err != nil || addr.IsUnspecified() {
+			continue
+		}
+		key := addr.Unmap().String()
+		h, ok := hosts[key]
+		if !ok {
+			h = &RemoteHost{
+				Addr:     key,
+				States:   make(map[string]int),
+				Loopback: addr.IsLoopback(),
+				Private:  addr.IsPrivate() || addr.IsLinkLocalUnicast(),
+			}
+			hosts[key] = h
+			procs[key] = make(map[int32]int)
+		}
+		h.Connections++
+		if !containsPort(h.Ports, conn.Raddr.Port) {
+			h.Ports = append(h.Ports, conn.Raddr.Port)
+		}
+		if conn.Status != "" {
+			h.States[conn.Status]++
+		}
+		if conn.Pid > 0 {
+			procs[key][conn.Pid]++
+		}
+	}
+
+	out := make([]RemoteHost, 0, len(hosts))
+	for key, h := range hosts {
+		h.Processes = make([]RemoteHostProcess, 0, len(procs[key]))
+		for pid, n := range procs[key] {
+			h.Processes = append(h.Processes, RemoteHostProcess{PID: pid, Name: names[pid], Connections: n})
+		}
+		sort.Slice(h.Processes, func(i, j int) bool { return h.Processes[i].Connections > h.Processes[j].Connections })
+		sort.Slice(h.Ports, func(i, j int) bool { return h.Ports[i] < h.Ports[j] })
+		if resolve && !h.Loopback {
+			h.Host = c.dns.lookup(h.Addr)
+		}
+		out = append(out, *h)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Connections != out[j].Connections {
+			return out[i].Connections > out[j].Connections
+		}
+		return out[i].Addr < out[j].Addr
+	})
+	return out, nil
+}
+
+func containsPort(ports []uint32, port uint32) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// drmClient — счётчики одного DRM-клиента из /proc/<pid>/fdinfo/<fd>.
+type drmClient struct {
+	PID         int32
+	Driver      string
+	PDev        string
+	ClientID    string
+	EngineNS    map[string]uint64 // drm-engine-<имя>: накопленное время, нс
+	Capacity    map[string]uint64 // drm-engine-capacity-<имя>: число экземпляров движка
+	Cycles      map[string]uint64 // drm-cycles-<имя> (xe)
+	TotalCycles map[string]uint64 // drm-total-cycles-<имя> (xe)
+	MemoryKiB   map[string]uint64 // drm-resident-<регион> или drm-memory-<регион>, KiB
+}
+
+func (c *drmClient) key() string {
+	return c.PDev + "/" + c.ClientID
+}
+
+// drmSampler хранит предыдущие счётчики клиентов, чтобы считать загрузку за интервал.
+type drmSampler struct {
+	present  bool
+	prev     map[string]drmClient
+	prevTime time.Time
+}
+
+func newDRMSampler() *drmSampler {
+	_, err := os.Stat("/dev/dri")
+	return &drmSampler{present: err == nil, prev: make(map[string]drmClient)}
+}
+
+// available сообщает, есть ли в системе DRM-устройства (иначе сканировать fdinfo незачем).
+func (s *drmSampler) available() bool { return s.present }
+
+// sample сканирует fdinfo всех процессов и возвращает загрузку по клиентам.
+// Клиенты, появившиеся после предыдущего вызова, попадают в результат только с памятью.
+func (s *drmSampler) sample() []drmUsage {
+	now := time.Now()
+	clients := scanDRMClients()
+	elapsedNS := float64(now.Sub(s.prevTime).Nanoseconds())
+	first := s.prevTime.IsZero()
+
+	out := make([]drmUsage, 0, len(clients))
+	next := make(map[string]drmClient, len(clients))
+	for _, c := range clients {
+		next[c.key()] = c
+		u := drmUsage{PID: c.PID, Driver: c.Driver, PDev: c.PDev, Engines: make(map[string]float64)}
+		u.MemoryBytes = c.deviceMemoryKiB() * 1024
+		if prev, ok := s.prev[c.key()]; ok && !first && elapsedNS > 0 {
+			for engine, ns := range c.EngineNS {
+				p, ok := prev.EngineNS[engine]
+				if !ok || ns < p {
+					continue
+				}
+				capacity := float64(c.Capacity[engine])
+				if capacity <= 0 {
+					capacity = 1
+				}
+				u.Engines[engine] = clampPercent(float64(ns-p) / elapsedNS / capacity * 100)
+			}
+			for engine, cycles := range c.Cycles {
+				total, pTotal := c.TotalCycles[engine], prev.TotalCycles[engine]
+				p, ok := prev.Cycles[engine]
+				if !ok || cycles < p || total <= pTotal {
+					continue
+				}
+				u.Engines[engine] = clampPercent(float64(cycles-p) / float64(total-pTotal) * 100)
+			}
+		}
+		out = append(out, u)
+	}
+	s.prev = next
+	s.prevTime = now
+	return out
+}
+
+// deviceMemoryKiB возвращает видеопамять клиента: регионы vram* (amdgpu — vram, xe — vram0…)
+// и local* (i915), а для встроенных GPU без собственной памяти — всё, что есть (system, gtt).
+func (c *drmClient) deviceMemoryKiB() uint64 {
+	var local, all uint64
+	for region, kib := range c.MemoryKiB {
+		all += kib
+		if strings.HasPrefix(region, "vram") || strings.HasPrefix(region, "local") {
+			local += kib
+		}
+	}
+	if local > 0 {
+		return local
+	}
+	return all
+}
+
+// scanDRMClients обходит /proc/*/fd и читает fdinfo для дескрипторов /dev/dri/*.
+// Один клиент может быть открыт через несколько fd (dup) — учитываем его один раз.
+func scanDRMClients() []drmClient {
+	procDirs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var out []drmClient
+	for _, d := range procDirs {
+		pid, err := strconv.ParseInt(d.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", d.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "/dev/dri/") {
+				continue
+			}
+			c, ok := readDRMFdinfo(filepath.Join("/proc", d.Name(), "fdinfo", fd.Name()))
+			if !ok || seen[c.key()] {
+				continue
+			}
+			seen[c.key()] = true
+			c.PID = int32(pid)
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func readDRMFdinfo(path string) (drmClient, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return drmClient{}, false
+	}
+	defer f.Close()
+
+	c := drmClient{
+		EngineNS:    make(map[string]uint64),
+		Capacity:    make(map[string]uint64),
+		Cycles:      make(map[string]uint64),
+		TotalCycles: make(map[string]uint64),
+		MemoryKiB:   make(map[string]uint64),
+	}
+	resident := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || !strings.HasPrefix(key, "drm-") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case key == "drm-driver":
+			c.Driver = value
+		case key == "drm-pdev":
+			c.PDev = value
+		case key == "drm-client-id":
+			c.ClientID = value
+		case strings.HasPrefix(key, "drm-engine-capacity-"):
+			c.Capacity[strings.TrimPrefix(key, "drm-engine-capacity-")] = parseFdinfoUint(value)
+		case strings.HasPrefix(key, "drm-engine-"):
+			c.EngineNS[strings.TrimPrefix(key, "drm-engine-")] = parseFdinfoUint(value)
+		case strings.HasPrefix(key, "drm-total-cycles-"):
+			c.TotalCycles[strings.TrimPrefix(key, "drm-total-cycles-")] = parseFdinfoUint(value)
+		case strings.HasPrefix(key, "drm-cycles-"):
+			c.Cycles[strings.TrimPrefix(key, "drm-cycles-")] = parseFdinfoUint(value)
+		case strings.HasPrefix(key, "drm-resident-"):
+			resident[strings.TrimPrefix(key, "drm-resident-")] = parseFdinfoKiB(value)
+		case strings.HasPrefix(key, "drm-memory-"):
+			c.MemoryKiB[strings.TrimPrefix(key, "drm-memory-")] = parseFdinfoKiB(value)
+		}
+	}
+	// drm-resident-* — современное имя drm-memory-*; если есть оба, берём resident.
+	for region, kib := range resident {
+		c.MemoryKiB[region] = kib
+	}
+	if c.Driver == "" || c.ClientID == "" {
+		return drmClient{}, false
+	}
+	return c, true
+}
+
+// parseFdinfoUint разбирает "9288864723 ns" или "28257900".
+func parseFdinfoUint(s string) uint64 {
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		s = s[:i]
+	}
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
+
+// parseFdinfoKiB разбирает "1024 KiB" / "2 MiB" / "512" (байты) в KiB.
+func parseFdinfoKiB(s string) uint64 {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0
+	}
+	n, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	if len(fields) == 1 {
+		return n / 1024
+	}
+	switch fields[1] {
+	case "MiB":
+		return n * 1024
+	case "GiB":
+		return n * 1024 * 1024
+	}
+	return n
+}
@@ -0,0 +1,103 @@
+//go:build linux
+
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadDRMFdinfo(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want drmClient
+		ok   bool
+	}{
+		{
+			name: "i915",
+			in: "pos:\t0\nflags:\t02100002\nmnt_id:\t26\ndrm-driver:\ti915\ndrm-pdev:\t0000:00:02.0\ndrm-client-id:\t7\n" +
+				"drm-engine-render:\t9288864723 ns\ndrm-engine-video:\t0 ns\ndrm-engine-capacity-video:\t2\n" +
+				"drm-total-system:\t2 MiB\ndrm-resident-system:\t1024 KiB\ndrm-memory-system:\t4096 KiB\n",
+			want: drmClient{
+				Driver: "i915", PDev: "0000:00:02.0", ClientID: "7",
+				EngineNS:    map[string]uint64{"render": 9288864723, "video": 0},
+				Capacity:    map[string]uint64{"video": 2},
+				Cycles:      map[string]uint64{},
+				TotalCycles: map[string]uint64{},
+				// drm-resident-system важнее drm-memory-system.
+				MemoryKiB: map[string]uint64{"system": 1024},
+			},
+			ok: true,
+		},
+		{
+			name: "xe",
+			in: "drm-driver:\txe\ndrm-pdev:\t0000:03:00.0\ndrm-client-id:\t42\n" +
+				"drm-cycles-rcs:\t28257900\ndrm-total-cycles-rcs:\t7655183225\ndrm-resident-vram0:\t1048576\n",
+			want: drmClient{
+				Driver: "xe", PDev: "0000:03:00.0", ClientID: "42",
+				EngineNS:    map[string]uint64{},
+				Capacity:    map[string]uint64{},
+				Cycles:      map[string]uint64{"rcs": 28257900},
+				TotalCycles: map[string]uint64{"rcs": 7655183225},
+				MemoryKiB:   map[string]uint64{"vram0": 1024},
+			},
+			ok: true,
+		},
+		{
+			name: "not a DRM client",
+			in:   "pos:\t0\nflags:\t02\nmnt_id:\t26\n",
+		},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, "fdinfo")
+		if err := os.WriteFile(path, []byte(tt.in), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, ok := readDRMFdinfo(path)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v, %v\nwant %+v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseFdinfoKiB(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+	}{
+		{"1024 KiB", 1024},
+		{"2 MiB", 2048},
+		{"1 GiB", 1 << 20},
+		{"4096", 4},
+		{"", 0},
+		{"n/a", 0},
+	}
+	for _, tt := range tests {
+		if got := parseFdinfoKiB(tt.in); got != tt.want {
+			t.Errorf("parseFdinfoKiB(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDeviceMemoryKiB(t *testing.T) {
+	tests := []struct {
+		name   string
+		memory map[string]uint64
+		want   uint64
+	}{
+		{"discrete counts only local memory", map[string]uint64{"vram0": 100, "local1": 50, "system": 900}, 150},
+		{"vram", map[string]uint64{"vram": 10, "gtt": 5}, 10},
+		{"integrated counts everything", map[string]uint64{"system": 300, "stolen": 20}, 320},
+		{"none", nil, 0},
+	}
+	for _, tt := range tests {
+		c := drmClient{MemoryKiB: tt.memory}
+		if got := c.deviceMemoryKiB(); got != tt.want {
+			t.Errorf("%s: deviceMemoryKiB = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
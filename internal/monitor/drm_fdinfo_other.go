@@ -0,0 +1,12 @@
+//go:build !linux
+
+package monitor
+
+// drmSampler — DRM fdinfo есть только на Linux.
+type drmSampler struct{}
+
+func newDRMSampler() *drmSampler { return &drmSampler{} }
+
+func (s *drmSampler) available() bool { return false }
+
+func (s *drmSampler) sample() []drmUsage { return nil }
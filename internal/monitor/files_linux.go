@@ -0,0 +1,243 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func procPath(pid int32, rest string) string {
+	return "/proc/" + strconv.Itoa(int(pid)) + "/" + rest
+}
+
+// ListOpenFiles возвращает открытые дескрипторы процесса из /proc/<pid>/fd по возрастанию номера.
+// Дескрипторы чужих процессов без root не читаются — ошибка fs.ErrPermission.
+func ListOpenFiles(pid int32) ([]OpenFile, error) {
+	dir := procPath(pid, "fd")
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrProcessNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]OpenFile, 0, len(entries))
+	for _, e := range entries {
+		fd, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		link, err := os.Readlink(dir + "/" + e.Name())
+		if err != nil {
+			continue // закрыт между ReadDir и Readlink
+		}
+		out = append(out, describeFD(pid, int32(fd), link))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FD < out[j].FD })
+	return out, nil
+}
+
+// describeFD определяет тип и режим дескриптора fd, ссылающегося на link.
+func describeFD(pid int32, fd int32, link string) OpenFile {
+	f := OpenFile{FD: fd, Path: link, Type: FileTypeOther}
+	name := strconv.Itoa(int(fd))
+	switch {
+	case strings.HasPrefix(link, "socket:"):
+		f.Type = FileTypeSocket
+	case strings.HasPrefix(link, "pipe:"):
+		f.Type = FileTypePipe
+	case strings.HasPrefix(link, "anon_inode:"):
+		f.Type = FileTypeAnon
+	case strings.HasPrefix(link, "/"):
+		f.Deleted = strings.HasSuffix(link, " (deleted)")
+		f.Path = strings.TrimSuffix(link, " (deleted)")
+		// stat по ссылке в /proc работает и для удалённых файлов.
+		if st, err := os.Stat(procPath(pid, "fd/"+name)); err == nil {
+			f.Type = fileModeType(st.Mode())
+		}
+	}
+	f.Mode = fdMode(pid, name)
+	return f
+}
+
+func fileModeType(m fs.FileMode) string {
+	switch {
+	case m.IsDir():
+		return FileTypeDir
+	case m&fs.ModeCharDevice != 0:
+		return FileTypeChar
+	case m&fs.ModeDevice != 0:
+		return FileTypeBlock
+	case m&fs.ModeNamedPipe != 0:
+		return FileTypeFIFO
+	case m&fs.ModeSocket != 0:
+		return FileTypeSocket
+	case m.IsRegular():
+		return FileTypeFile
+	}
+	return FileTypeOther
+}
+
+// fdMode читает режим доступа из строки "flags:" в /proc/<pid>/fdinfo/<fd> (восьмеричное число).
+func fdMode(pid int32, fd string) string {
+	data, err := os.ReadFile(procPath(pid, "fdinfo/"+fd))
+	if err != nil {
+		return ""
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		v, ok := strings.CutPrefix(sc.Text(), "flags:")
+		if !ok {
+			continue
+		}
+		flags, err := strconv.ParseUint(strings.TrimSpace(v), 8, 64)
+		if err != nil {
+			return ""
+		}
+		switch flags & 3 { // O_ACCMODE
+		case 0:
+			return "r"
+		case 1:
+			return "w"
+		default:
+			return "rw"
+		}
+	}
+	return ""
+}
+
+// FindFileHolders ищет процессы, которые держат path или что-то внутри каталога path:
+// открытым дескриптором, рабочим или корневым каталогом, исполняемым файлом или
+// отображением в память. Процессы просматриваются параллельно, не больше fileScanWorkers сразу.
+func FindFileHolders(ctx context.Context, path string) (FileHolders, error) {
+	target, err := holderTarget(path)
+	if err != nil {
+		return FileHolders{}, err
+	}
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return FileHolders{}, err
+	}
+	res := FileHolders{Path: target, Holders: []FileHolder{}, Scanned: len(pids)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan int32)
+	for range min(fileScanWorkers, len(pids)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pid := range queue {
+				uses, denied := scanFileUses(pid, target)
+				if len(uses) == 0 && !denied {
+					continue
+				}
+				h := FileHolder{PID: pid, Uses: uses}
+				if len(uses) > 0 {
+					if p, err := process.NewProcess(pid); err == nil {
+						h.Name = processName(p)
+						h.Username, _ = p.Username()
+					}
+				}
+				mu.Lock()
+				if denied {
+					res.Denied++
+				}
+				if len(uses) > 0 {
+					res.Holders = append(res.Holders, h)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, pid := range pids {
+		select {
+		case queue <- pid:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return FileHolders{}, err
+	}
+	sort.Slice(res.Holders, func(i, j int) bool { return res.Holders[i].PID < res.Holders[j].PID })
+	return res, nil
+}
+
+// scanFileUses возвращает, чем процесс pid держит target; denied — таблицу дескрипторов
+// прочитать не дали.
+func scanFileUses(pid int32, target string) (uses []FileUse, denied bool) {
+	for _, use := range []string{FileUseCwd, FileUseRoot, FileUseExe} {
+		if link, err := os.Readlink(procPath(pid, use)); err == nil && underPath(link, target) {
+			uses = append(uses, FileUse{
+				Use:     use,
+				Path:    strings.TrimSuffix(link, " (deleted)"),
+				Deleted: strings.HasSuffix(link, " (deleted)"),
+			})
+		}
+	}
+	dir := procPath(pid, "fd")
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrPermission) {
+		denied = true
+	}
+	for _, e := range entries {
+		link, err := os.Readlink(dir + "/" + e.Name())
+		if err != nil || !underPath(link, target) {
+			continue
+		}
+		fd, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		f := describeFD(pid, int32(fd), link)
+		uses = append(uses, FileUse{Use: FileUseFD, FD: &f.FD, Path: f.Path, Type: f.Type, Mode: f.Mode, Deleted: f.Deleted})
+	}
+	uses = append(uses, mappedFileUses(pid, target)...)
+	return uses, denied
+}
+
+// mappedFileUses — файлы под target, отображённые в память процесса (/proc/<pid>/maps),
+// каждый один раз.
+func mappedFileUses(pid int32, target string) []FileUse {
+	data, err := os.ReadFile(procPath(pid, "maps"))
+	if err != nil {
+		return nil
+	}
+	var uses []FileUse
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		// адреса, права, смещение, устройство, inode, путь (может содержать пробелы).
+		line := sc.Text()
+		i := strings.Index(line, " /")
+		if i < 0 {
+			continue
+		}
+		path := line[i+1:]
+		if seen[path] || !underPath(path, target) {
+			continue
+		}
+		seen[path] = true
+		uses = append(uses, FileUse{
+			Use:     FileUseMmap,
+			Path:    strings.TrimSuffix(path, " (deleted)"),
+			Deleted: strings.HasSuffix(path, " (deleted)"),
+		})
+	}
+	return uses
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestFdMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		flag int
+		want string
+	}{
+		{os.O_RDONLY, "r"},
+		{os.O_WRONLY, "w"},
+		{os.O_WRONLY | os.O_APPEND, "w"},
+		{os.O_RDWR, "rw"},
+	}
+	pid := int32(os.Getpid())
+	for _, tt := range tests {
+		f, err := os.OpenFile(path, tt.flag, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := fdMode(pid, strconv.Itoa(int(f.Fd())))
+		f.Close()
+		if got != tt.want {
+			t.Errorf("fdMode(flags %#o) = %q, want %q", tt.flag, got, tt.want)
+		}
+	}
+	if got := fdMode(pid, "999999"); got != "" {
+		t.Errorf("fdMode(closed fd) = %q, want empty", got)
+	}
+}
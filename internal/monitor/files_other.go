@@ -0,0 +1,21 @@
+//go:build !linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+)
+
+// ListOpenFiles — таблица дескрипторов читается только из /proc Linux.
+func ListOpenFiles(int32) ([]OpenFile, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// FindFileHolders — см. ListOpenFiles.
+func FindFileHolders(_ context.Context, path string) (FileHolders, error) {
+	if _, err := holderTarget(path); err != nil {
+		return FileHolders{}, err
+	}
+	return FileHolders{}, errors.ErrUnsupported
+}
@@ -1,86 +1,89 @@
 package monitor
 
 import (
-	"bufio"
-	"bytes"
-	"context"
-	"os/exec"
-	"strconv"
+	"fmt"
 	"strings"
-	"time"
 )
 
-// GPUStats — результат сбора метрик GPU.
-type GPUStats struct {
-	UtilPercent   float64
-	Name          string
-	TempC         int
-	MemoryUsedMB  uint64
-	MemoryTotalMB uint64
+// GPUInfo — метрики одного GPU.
+type GPUInfo struct {
+	Index         int                `json:"index"`
+	Vendor        string             `json:"vendor"` // nvidia / intel
+	BusID         string             `json:"bus_id,omitempty"`
+	Name          string             `json:"name"`
+	UtilPercent   float64            `json:"utilization_percent"`
+	MemoryUsedMB  uint64             `json:"memory_used_mb,omitempty"`
+	MemoryTotalMB uint64             `json:"memory_total_mb,omitempty"`
+	TempC         int                `json:"temp_c,omitempty"`
+	PowerW        float64            `json:"power_w,omitempty"`
+	PowerLimitW   float64            `json:"power_limit_w,omitempty"`
+	FanPercent    float64            `json:"fan_percent,omitempty"`
+	FreqMHz       int                `json:"freq_mhz,omitempty"` // SM / GT частота
+	MaxFreqMHz    int                `json:"max_freq_mhz,omitempty"`
+	MemoryFreqMHz int                `json:"memory_freq_mhz,omitempty"`
+	Engines       map[string]float64 `json:"engines,omitempty"` // загрузка по движкам (render, video, ...), %
+	// Только NVIDIA.
+	EncoderPercent  float64  `json:"encoder_percent,omitempty"`
+	DecoderPercent  float64  `json:"decoder_percent,omitempty"`
+	PCIeRxMBs       float64  `json:"pcie_rx_mb_s,omitempty"`
+	PCIeTxMBs       float64  `json:"pcie_tx_mb_s,omitempty"`
+	ThrottleReasons []string `json:"throttle_reasons,omitempty"`
 }
 
-// getGPUStats возвращает загрузку первого GPU (%), название, температуру (°C) и видеопамять (МБ).
-// Использует nvidia-smi (Windows/Linux с драйверами NVIDIA).
-func getGPUStats() GPUStats {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "nvidia-smi",
-		"--query-gpu=utilization.gpu,name,temperature.gpu,memory.used,memory.total",
-		"--format=csv,noheader,nounits",
-	)
-	setProcessNoWindow(cmd)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	if err := cmd.Run(); err != nil {
-		return GPUStats{}
-	}
+// drmUsage — загрузка движков одним DRM-клиентом за интервал между двумя сэмплами.
+type drmUsage struct {
+	PID         int32
+	Driver      string
+	PDev        string
+	Engines     map[string]float64 // % занятости движка (с учётом capacity)
+	MemoryBytes uint64
+}
 
-	scanner := bufio.NewScanner(&out)
-	if !scanner.Scan() {
-		return GPUStats{}
+// aggregateGPUs сворачивает список GPU в одну запись для плоских полей Stats:
+// средняя загрузка, суммарная память, максимальная температура; частота и движки — первого GPU.
+func aggregateGPUs(gpus []GPUInfo) GPUInfo {
+	if len(gpus) == 0 {
+		return GPUInfo{}
 	}
-	line := strings.TrimSpace(scanner.Text())
-	// Формат: "35, NVIDIA GeForce RTX 3060, 49, 2048, 12288" (util%, name, temp, mem_used_MiB, mem_total_MiB)
-	parts := strings.Split(line, ", ")
-	if len(parts) < 1 {
-		return GPUStats{}
+	if len(gpus) == 1 {
+		return gpus[0]
 	}
-	pctStr := strings.TrimSpace(parts[0])
-	pctStr = strings.TrimSuffix(pctStr, "%")
-	pct, _ := strconv.ParseFloat(strings.TrimSpace(pctStr), 64)
-	name := ""
-	if len(parts) > 1 {
-		name = strings.TrimSpace(parts[1])
-		name = strings.Trim(name, `"`)
+	agg := GPUInfo{
+		Vendor:     gpus[0].Vendor,
+		FreqMHz:    gpus[0].FreqMHz,
+		MaxFreqMHz: gpus[0].MaxFreqMHz,
+		Engines:    gpus[0].Engines,
 	}
-	temp := 0
-	if len(parts) > 2 {
-		tempStr := strings.TrimSpace(parts[2])
-		tempStr = strings.TrimSuffix(tempStr, " C")
-		if t, err := strconv.Atoi(strings.TrimSpace(tempStr)); err == nil {
-			temp = t
+	sameName := true
+	names := make([]string, 0, len(gpus))
+	for _, g := range gpus {
+		agg.UtilPercent += g.UtilPercent
+		agg.MemoryUsedMB += g.MemoryUsedMB
+		agg.MemoryTotalMB += g.MemoryTotalMB
+		agg.PowerW += g.PowerW
+		if g.TempC > agg.TempC {
+			agg.TempC = g.TempC
 		}
+		if g.Name != gpus[0].Name {
+			sameName = false
+		}
+		names = append(names, g.Name)
 	}
-	memUsed := uint64(0)
-	memTotal := uint64(0)
-	if len(parts) > 3 {
-		memUsed, _ = parseMiB(parts[3])
-	}
-	if len(parts) > 4 {
-		memTotal, _ = parseMiB(parts[4])
-	}
-	return GPUStats{
-		UtilPercent:   pct,
-		Name:          name,
-		TempC:         temp,
-		MemoryUsedMB:  memUsed,
-		MemoryTotalMB: memTotal,
+	agg.UtilPercent /= float64(len(gpus))
+	if sameName {
+		agg.Name = fmt.Sprintf("%d × %s", len(gpus), gpus[0].Name)
+	} else {
+		agg.Name = strings.Join(names, ", ")
 	}
+	return agg
 }
 
-func parseMiB(s string) (uint64, error) {
-	s = strings.TrimSpace(s)
-	s = strings.TrimSuffix(s, " MiB")
-	return strconv.ParseUint(s, 10, 64)
+func clampPercent(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
 }
@@ -0,0 +1,170 @@
+//go:build linux
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const intelVendorID = "0x8086"
+
+var drmCardRe = regexp.MustCompile(`^card\d+$`)
+
+// intelCard — пути sysfs одной карты Intel (i915 или xe).
+type intelCard struct {
+	Card      string // card0
+	Driver    string // i915 / xe
+	PDev      string // 0000:00:02.0
+	DeviceID  string // 0x9a49
+	freqFile  string
+	maxFile   string
+	idleFile  string // rc6_residency_ms (i915) или idle_residency_ms (xe)
+	hwmonTemp string
+}
+
+// intelGPU считает загрузку iGPU Intel по RC6/idle residency и DRM fdinfo.
+type intelGPU struct {
+	cards    []intelCard
+	prevIdle map[string]uint64
+	prevTime time.Time
+}
+
+func newIntelGPU() *intelGPU {
+	return &intelGPU{cards: findIntelCards(), prevIdle: make(map[string]uint64)}
+}
+
+// findIntelCards ищет /sys/class/drm/cardN с vendor 0x8086 и драйвером i915/xe.
+func findIntelCards() []intelCard {
+	entries, err := os.ReadDir("/sys/class/drm")
+	if err != nil {
+		return nil
+	}
+	var cards []intelCard
+	for _, e := range entries {
+		if !drmCardRe.MatchString(e.Name()) {
+			continue
+		}
+		base := filepath.Join("/sys/class/drm", e.Name())
+		dev := filepath.Join(base, "device")
+		if readSysfsString(filepath.Join(dev, "vendor")) != intelVendorID {
+			continue
+		}
+		driverLink, err := os.Readlink(filepath.Join(dev, "driver"))
+		if err != nil {
+			continue
+		}
+		devLink, _ := filepath.EvalSymlinks(dev)
+		c := intelCard{
+			Card:     e.Name(),
+			Driver:   filepath.Base(driverLink),
+			PDev:     filepath.Base(devLink),
+			DeviceID: readSysfsString(filepath.Join(dev, "device")),
+		}
+		switch c.Driver {
+		case "i915":
+			c.freqFile = firstExisting(filepath.Join(base, "gt_act_freq_mhz"), filepath.Join(base, "gt_cur_freq_mhz"))
+			c.maxFile = firstExisting(filepath.Join(base, "gt_max_freq_mhz"), filepath.Join(base, "gt_RP0_freq_mhz"))
+			c.idleFile = firstExisting(filepath.Join(base, "gt", "gt0", "rc6_residency_ms"), filepath.Join(base, "power", "rc6_residency_ms"))
+		case "xe":
+			gt := filepath.Join(dev, "tile0", "gt0")
+			c.freqFile = firstExisting(filepath.Join(gt, "freq0", "act_freq"), filepath.Join(gt, "freq0", "cur_freq"))
+			c.maxFile = firstExisting(filepath.Join(gt, "freq0", "max_freq"), filepath.Join(gt, "freq0", "rp0_freq"))
+			c.idleFile = firstExisting(filepath.Join(gt, "gtidle", "idle_residency_ms"))
+		default:
+			continue
+		}
+		if matches, _ := filepath.Glob(filepath.Join(dev, "hwmon", "hwmon*", "temp1_input")); len(matches) > 0 {
+			c.hwmonTemp = matches[0]
+		}
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+// available сообщает, найдены ли карты Intel при старте.
+func (g *intelGPU) available() bool { return len(g.cards) > 0 }
+
+// sample возвращает метрики Intel GPU. usage — загрузка DRM-клиентов из drmSampler.
+func (g *intelGPU) sample(usage []drmUsage) []GPUInfo {
+	if len(g.cards) == 0 {
+		return nil
+	}
+	now := time.Now()
+	elapsedMS := float64(now.Sub(g.prevTime).Milliseconds())
+	first := g.prevTime.IsZero()
+	g.prevTime = now
+
+	out := make([]GPUInfo, 0, len(g.cards))
+	for _, c := range g.cards {
+		s := GPUInfo{
+			Name:    fmt.Sprintf("Intel Graphics (%s)", strings.TrimPrefix(c.DeviceID, "0x")),
+			Vendor:  "intel",
+			BusID:   c.PDev,
+			Engines: make(map[string]float64),
+		}
+		s.FreqMHz = int(readSysfsUint(c.freqFile))
+		s.MaxFreqMHz = int(readSysfsUint(c.maxFile))
+		if c.hwmonTemp != "" {
+			s.TempC = int(readSysfsUint(c.hwmonTemp) / 1000)
+		}
+
+		// Занятость = доля времени вне RC6 (idle) за интервал.
+		if c.idleFile != "" {
+			idle := readSysfsUint(c.idleFile)
+			if prev, ok := g.prevIdle[c.Card]; ok && !first && elapsedMS > 0 && idle >= prev {
+				s.UtilPercent = clampPercent(100 - float64(idle-prev)/elapsedMS*100)
+			}
+			g.prevIdle[c.Card] = idle
+		}
+
+		for _, u := range usage {
+			if u.PDev != c.PDev {
+				continue
+			}
+			for engine, pct := range u.Engines {
+				s.Engines[engine] = clampPercent(s.Engines[engine] + pct)
+			}
+		}
+		// Без RC6 (например, в контейнере) берём самый загруженный движок.
+		if c.idleFile == "" {
+			for _, pct := range s.Engines {
+				if pct > s.UtilPercent {
+					s.UtilPercent = pct
+				}
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func firstExisting(paths ...string) string {
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+func readSysfsString(path string) string {
+	if path == "" {
+		return ""
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func readSysfsUint(path string) uint64 {
+	n, _ := strconv.ParseUint(readSysfsString(path), 10, 64)
+	return n
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package monitor
+
+// intelGPU — метрики Intel iGPU доступны только на Linux (sysfs i915/xe).
+type intelGPU struct{}
+
+func newIntelGPU() *intelGPU { return &intelGPU{} }
+
+func (g *intelGPU) available() bool { return false }
+
+func (g *intelGPU) sample(usage []drmUsage) []GPUInfo { return nil }
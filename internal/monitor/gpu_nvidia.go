@@ -0,0 +1,346 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Базовый набор полей поддерживается любым драйвером; расширенный — начиная с R470+.
+// Если драйвер не знает какое-то поле, nvidia-smi падает целиком — тогда откатываемся на базовый.
+const (
+	nvidiaGPUQueryBasic    = "index,pci.bus_id,name,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw,fan.speed"
+	nvidiaGPUQueryExtended = nvidiaGPUQueryBasic + ",power.limit,clocks.sm,clocks.max.sm,clocks.mem,utilization.encoder,utilization.decoder,clocks_throttle_reasons.active"
+)
+
+// Биты clocks_throttle_reasons.active (nvmlClocksThrottleReasons).
+var nvidiaThrottleReasons = []struct {
+	bit  uint64
+	name string
+}{
+	{0x1, "gpu_idle"},
+	{0x2, "applications_clocks_setting"},
+	{0x4, "sw_power_cap"},
+	{0x8, "hw_slowdown"},
+	{0x10, "sync_boost"},
+	{0x20, "sw_thermal_slowdown"},
+	{0x40, "hw_thermal_slowdown"},
+	{0x80, "hw_power_brake_slowdown"},
+	{0x100, "display_clock_setting"},
+}
+
+// GPUProcess — процесс, использующий GPU NVIDIA (compute и/или graphics).
+type GPUProcess struct {
+	PID          int32  `json:"pid"`
+	GPUIndex     int    `json:"gpu_index"`
+	BusID        string `json:"bus_id,omitempty"`
+	Type         string `json:"type"` // compute / graphics / compute+graphics
+	Name         string `json:"name,omitempty"`
+	UsedMemoryMB uint64 `json:"used_memory_mb"`
+}
+
+// nvidiaSMI запускает исполняемый файл nvidia-smi и разбирает его вывод.
+// Путь настраивается, чтобы в тестах можно было подставить фейковый nvidia-smi.
+type nvidiaSMI struct {
+	path       string
+	basicQuery bool // драйвер не поддерживает расширенный запрос; меняется только из nvidiaSampler.run
+}
+
+func newNvidiaSMI(path string) *nvidiaSMI {
+	if path == "" {
+		path = "nvidia-smi"
+	}
+	return &nvidiaSMI{path: path}
+}
+
+func (n *nvidiaSMI) run(timeout time.Duration, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, n.path, args...)
+	setProcessNoWindow(cmd)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// command создаёт команду nvidia-smi для долгоживущего процесса (остановка — через ctx).
+func (n *nvidiaSMI) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, n.path, args...)
+	setProcessNoWindow(cmd)
+	return cmd
+}
+
+// probe проверяет, что nvidia-smi запускается и видит хотя бы один GPU.
+func (n *nvidiaSMI) probe() bool {
+	out, err := n.run(5*time.Second, "-L")
+	return err == nil && len(bytes.TrimSpace(out)) > 0
+}
+
+// gpuQuery возвращает аргументы потокового опроса GPU раз в interval.
+func (n *nvidiaSMI) gpuQuery(interval time.Duration) []string {
+	query := nvidiaGPUQueryExtended
+	if n.basicQuery {
+		query = nvidiaGPUQueryBasic
+	}
+	return []string{"--query-gpu=" + query, "--format=csv,noheader,nounits", fmt.Sprintf("--loop-ms=%d", interval.Milliseconds())}
+}
+
+// pcieQuery возвращает аргументы потокового dmon по PCIe-трафику (шаг — секунды, минимум 1).
+func (n *nvidiaSMI) pcieQuery(interval time.Duration) []string {
+	sec := int(interval.Seconds())
+	if sec < 1 {
+		sec = 1
+	}
+	return []string{"dmon", "-s", "t", "-d", strconv.Itoa(sec)}
+}
+
+// pmonQuery возвращает аргументы потокового pmon: процессы на GPU с видеопамятью (-s m)
+// и временем замера (-o T), по которому строки делятся на замеры.
+func (n *nvidiaSMI) pmonQuery(interval time.Duration) []string {
+	sec := int(interval.Seconds())
+	if sec < 1 {
+		sec = 1
+	}
+	return []string{"pmon", "-s", "m", "-o", "T", "-d", strconv.Itoa(sec)}
+}
+
+// computeApps возвращает compute-процессы из --query-compute-apps — если pmon не
+// поддерживается. Graphics-процессы так не видны.
+func (n *nvidiaSMI) computeApps() []GPUProcess {
+	out, err := n.run(5*time.Second, "--query-compute-apps=pid,gpu_bus_id,process_name,used_memory", "--format=csv,noheader,nounits")
+	if err != nil {
+		return nil
+	}
+	return parseNvidiaComputeApps(bytes.NewReader(out))
+}
+
+// nvidiaFieldError сообщает, что nvidia-smi отверг поле запроса, например
+// `Field "clocks.max.sm" is not a valid field to query.`
+func nvidiaFieldError(stderr string) bool {
+	s := strings.ToLower(stderr)
+	if !strings.Contains(s, "field") {
+		return false
+	}
+	return strings.Contains(s, "not a valid") || strings.Contains(s, "invalid") ||
+		strings.Contains(s, "not supported") || strings.Contains(s, "unsupported")
+}
+
+// parseNvidiaGPUs разбирает CSV nvidia-smi, по строке на GPU:
+// "0, 00000000:01:00.0, NVIDIA GeForce RTX 3060, 35, 2048, 12288, 49, 31.20, 30[, 170.00, 1770, 2100, 7500, 0, 0, 0x0000000000000000]".
+// Поля "[N/A]" / "[Not Supported]" дают нулевые значения.
+func parseNvidiaGPUs(r io.Reader) []GPUInfo {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	var gpus []GPUInfo
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(rec) < 3 {
+			continue
+		}
+		field := func(i int) string {
+			if i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		g := GPUInfo{Vendor: "nvidia", BusID: field(1), Name: strings.Trim(field(2), `"`)}
+		g.Index, _ = strconv.Atoi(field(0))
+		g.UtilPercent = parseNvidiaFloat(field(3))
+		g.MemoryUsedMB = uint64(parseNvidiaFloat(field(4)))
+		g.MemoryTotalMB = uint64(parseNvidiaFloat(field(5)))
+		g.TempC = int(parseNvidiaFloat(field(6)))
+		g.PowerW = parseNvidiaFloat(field(7))
+		g.FanPercent = parseNvidiaFloat(field(8))
+		g.PowerLimitW = parseNvidiaFloat(field(9))
+		g.FreqMHz = int(parseNvidiaFloat(field(10)))
+		g.MaxFreqMHz = int(parseNvidiaFloat(field(11)))
+		g.MemoryFreqMHz = int(parseNvidiaFloat(field(12)))
+		g.EncoderPercent = parseNvidiaFloat(field(13))
+		g.DecoderPercent = parseNvidiaFloat(field(14))
+		g.ThrottleReasons = decodeThrottleReasons(field(15))
+		gpus = append(gpus, g)
+	}
+	return gpus
+}
+
+// parseNvidiaFloat разбирает число из nvidia-smi, отбрасывая единицы ("35 %", "2048 MiB").
+func parseNvidiaFloat(s string) float64 {
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		s = s[:i]
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// decodeThrottleReasons переводит битовую маску "0x0000000000000004" в имена причин.
+func decodeThrottleReasons(s string) []string {
+	mask, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 64)
+	if err != nil || mask == 0 {
+		return nil
+	}
+	var out []string
+	for _, r := range nvidiaThrottleReasons {
+		if mask&r.bit != 0 {
+			out = append(out, r.name)
+		}
+	}
+	return out
+}
+
+// parseNvidiaDmonPCIe разбирает вывод dmon -s t:
+//
+//	# gpu   rxpci   txpci
+//	# Idx    MB/s    MB/s
+//	    0      12       3
+func parseNvidiaDmonPCIe(r io.Reader) map[int][2]float64 {
+	out := make(map[int][2]float64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		idx, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		out[idx] = [2]float64{parseNvidiaFloat(fields[1]), parseNvidiaFloat(fields[2])}
+	}
+	return out
+}
+
+// parseNvidiaComputeApps разбирает CSV --query-compute-apps: "1234, 00000000:01:00.0, python3, 2048".
+func parseNvidiaComputeApps(r io.Reader) []GPUProcess {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	var out []GPUProcess
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(rec) < 4 {
+			continue
+		}
+		pid, err := strconv.ParseInt(strings.TrimSpace(rec[0]), 10, 32)
+		if err != nil {
+			continue
+		}
+		out = append(out, GPUProcess{
+			PID:          int32(pid),
+			BusID:        strings.TrimSpace(rec[1]),
+			Type:         "compute",
+			Name:         strings.TrimSpace(rec[2]),
+			UsedMemoryMB: uint64(parseNvidiaFloat(strings.TrimSpace(rec[3]))),
+		})
+	}
+	return out
+}
+
+// nvidiaPmon собирает строки "nvidia-smi pmon -s m -o T" в замеры:
+//
+//	#Time        gpu         pid   type     fb   ccpm   command
+//	#HH:MM:SS    Idx           #    C/G     MB     MB   name
+//	 12:00:01      0       2183     G     77      0   Xorg
+//	 12:00:01      1          -     -      -      -   -
+//
+// Набор колонок зависит от драйвера, поэтому они берутся из заголовка. Замер закончен,
+// когда сменилось время (без колонки времени — когда процесс встретился повторно).
+type nvidiaPmon struct {
+	cols  map[string]int
+	time  string
+	seen  map[[2]int64]bool
+	batch []GPUProcess
+}
+
+// line разбирает строку pmon; если она начинает новый замер, возвращает предыдущий и true.
+func (p *nvidiaPmon) line(s string) ([]GPUProcess, bool) {
+	if strings.HasPrefix(s, "#") {
+		names := strings.Fields(strings.ToLower(strings.TrimLeft(s, "#")))
+		if slices.Contains(names, "pid") {
+			p.cols = make(map[string]int, len(names))
+			for i, name := range names {
+				p.cols[name] = i
+			}
+		}
+		return nil, false
+	}
+	if p.cols == nil {
+		return nil, false
+	}
+	fields := strings.Fields(s)
+	field := func(name string) string {
+		if i, ok := p.cols[name]; ok && i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+	gpu, err := strconv.Atoi(field("gpu"))
+	if err != nil {
+		return nil, false
+	}
+	// "-" вместо pid — на GPU нет процессов.
+	pid, _ := strconv.ParseInt(field("pid"), 10, 32)
+	key := [2]int64{int64(gpu), pid}
+	_, timed := p.cols["time"]
+
+	var done []GPUProcess
+	finished := p.seen != nil && ((timed && field("time") != p.time) || (!timed && p.seen[key]))
+	if finished {
+		done, p.batch, p.seen = p.batch, nil, nil
+	}
+	if p.seen == nil {
+		p.seen, p.time = make(map[[2]int64]bool), field("time")
+	}
+	p.seen[key] = true
+	if pid <= 0 {
+		return done, finished
+	}
+	name := ""
+	if i, ok := p.cols["command"]; ok && i < len(fields) && fields[i] != "-" {
+		name = strings.Join(fields[i:], " ")
+	}
+	p.batch = append(p.batch, GPUProcess{
+		PID:          int32(pid),
+		GPUIndex:     gpu,
+		Type:         nvidiaProcessType(field("type")),
+		Name:         name,
+		UsedMemoryMB: uint64(parseNvidiaFloat(field("fb"))),
+	})
+	return done, finished
+}
+
+// nvidiaProcessType переводит тип процесса nvidia-smi (C, G, C+G) в GPUProcess.Type.
+func nvidiaProcessType(s string) string {
+	switch s {
+	case "G":
+		return "graphics"
+	case "C+G":
+		return "compute+graphics"
+	}
+	return "compute"
+}
@@ -0,0 +1,307 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Повторная проверка наличия nvidia-smi: 10 с, 20 с, ... до 5 мин.
+	nvidiaProbeMin = 10 * time.Second
+	nvidiaProbeMax = 5 * time.Minute
+	// Перезапуск упавшего потокового процесса: 1 с, 2 с, ... до 30 с.
+	nvidiaRestartMin = time.Second
+	nvidiaRestartMax = 30 * time.Second
+	// Процесс, проработавший дольше, считается здоровым — задержка перезапуска сбрасывается.
+	nvidiaHealthyRun = time.Minute
+	// Опрос --query-compute-apps, если pmon не поддерживается, — реже, чем метрики.
+	nvidiaProcsEvery = 5 * time.Second
+	// Сколько stderr nvidia-smi хранить для разбора ошибки.
+	nvidiaStderrMax = 4096
+)
+
+// nvidiaSampler держит долгоживущие процессы nvidia-smi (--loop-ms, dmon и pmon),
+// построчно разбирает их CSV и отдаёт последние значения без запуска процессов на каждый тик.
+// Если nvidia-smi нет, наличие перепроверяется с нарастающей задержкой.
+type nvidiaSampler struct {
+	smi      *nvidiaSMI
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mu        sync.Mutex
+	available bool
+	gpus      map[int]nvidiaGPUSample
+	pcie      map[int][2]float64
+	procs     []GPUProcess
+	procsAt   time.Time
+}
+
+type nvidiaGPUSample struct {
+	info GPUInfo
+	at   time.Time
+}
+
+func newNvidiaSampler(smi *nvidiaSMI, interval time.Duration) *nvidiaSampler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &nvidiaSampler{
+		smi:      smi,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		gpus:     make(map[int]nvidiaGPUSample),
+		pcie:     make(map[int][2]float64),
+	}
+	go s.run()
+	return s
+}
+
+// stop завершает фоновые процессы nvidia-smi.
+func (s *nvidiaSampler) stop() {
+	s.cancel()
+}
+
+// sample возвращает последние метрики GPU и процессов; не блокируется на nvidia-smi.
+func (s *nvidiaSampler) sample() ([]GPUInfo, []GPUProcess) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.available {
+		return nil, nil
+	}
+
+	// Строки старше нескольких интервалов — GPU пропал или поток завис.
+	staleAfter := 3*s.interval + 2*time.Second
+	now := time.Now()
+	gpus := make([]GPUInfo, 0, len(s.gpus))
+	byBus := make(map[string]int, len(s.gpus))
+	for idx, g := range s.gpus {
+		if now.Sub(g.at) > staleAfter {
+			delete(s.gpus, idx)
+			continue
+		}
+		info := g.info
+		if t, ok := s.pcie[idx]; ok {
+			info.PCIeRxMBs, info.PCIeTxMBs = t[0], t[1]
+		}
+		byBus[strings.ToLower(info.BusID)] = idx
+		gpus = append(gpus, info)
+	}
+	sort.Slice(gpus, func(i, j int) bool { return gpus[i].Index < gpus[j].Index })
+
+	if now.Sub(s.procsAt) > staleAfter+nvidiaProcsEvery {
+		return gpus, nil
+	}
+	procs := make([]GPUProcess, len(s.procs))
+	copy(procs, s.procs)
+	// У pmon есть индекс GPU, у --query-compute-apps — только шина.
+	for i := range procs {
+		if procs[i].BusID != "" {
+			procs[i].GPUIndex = byBus[strings.ToLower(procs[i].BusID)]
+		}
+	}
+	return gpus, procs
+}
+
+func (s *nvidiaSampler) setProcesses(procs []GPUProcess) {
+	s.mu.Lock()
+	s.procs = procs
+	s.procsAt = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *nvidiaSampler) setAvailable(v bool) {
+	s.mu.Lock()
+	s.available = v
+	if !v {
+		s.gpus = make(map[int]nvidiaGPUSample)
+		s.pcie = make(map[int][2]float64)
+		s.procs = nil
+	}
+	s.mu.Unlock()
+}
+
+// run — главный цикл: проверка наличия nvidia-smi с backoff, затем потоковый опрос с перезапусками.
+func (s *nvidiaSampler) run() {
+	probeDelay := nvidiaProbeMin
+	for {
+		if !s.smi.probe() {
+			s.setAvailable(false)
+			if !s.sleep(probeDelay) {
+				return
+			}
+			probeDelay = min(probeDelay*2, nvidiaProbeMax)
+			continue
+		}
+		probeDelay = nvidiaProbeMin
+		s.setAvailable(true)
+
+		streamCtx, cancelStreams := context.WithCancel(s.ctx)
+		go s.keepStreaming(streamCtx, s.smi.pcieQuery, s.handlePCIeLine)
+		go s.streamProcesses(streamCtx)
+		s.streamGPUs(streamCtx)
+		cancelStreams()
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// streamGPUs перезапускает поток --query-gpu --loop-ms, пока nvidia-smi работает.
+// Возвращается, когда перезапуски перестали помогать (драйвер пропал) или sampler остановлен.
+func (s *nvidiaSampler) streamGPUs(ctx context.Context) {
+	delay := nvidiaRestartMin
+	for {
+		started := time.Now()
+		_, stderr, _ := s.stream(ctx, s.smi.gpuQuery(s.interval), s.handleGPULine)
+		if ctx.Err() != nil {
+			return
+		}
+		healthy := time.Since(started) > nvidiaHealthyRun
+		switch {
+		case !s.smi.basicQuery && nvidiaFieldError(stderr):
+			// Драйвер не знает какое-то поле расширенного запроса.
+			s.smi.basicQuery = true
+			continue
+		case s.smi.basicQuery && healthy:
+			// Базовый запрос работал и завершился (например, обновили драйвер) — пробуем расширенный снова.
+			s.smi.basicQuery = false
+		}
+		if healthy {
+			delay = nvidiaRestartMin
+		}
+		if !s.sleep(delay) {
+			return
+		}
+		if delay == nvidiaRestartMax && !s.smi.probe() {
+			return
+		}
+		delay = min(delay*2, nvidiaRestartMax)
+	}
+}
+
+// keepStreaming держит вспомогательный поток (dmon, pmon) запущенным до отмены ctx.
+// Возвращает false, если первый же запуск не дал ни строки — команда не поддерживается.
+func (s *nvidiaSampler) keepStreaming(ctx context.Context, args func(time.Duration) []string, handle func(string)) bool {
+	delay := nvidiaRestartMin
+	for first := true; ; first = false {
+		started := time.Now()
+		lines, _, _ := s.stream(ctx, args(s.interval), handle)
+		if ctx.Err() != nil {
+			return true
+		}
+		if first && lines == 0 {
+			return false
+		}
+		if time.Since(started) > nvidiaHealthyRun {
+			delay = nvidiaRestartMin
+		}
+		select {
+		case <-ctx.Done():
+			return true
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, nvidiaRestartMax)
+	}
+}
+
+// streamProcesses держит поток pmon; если pmon не поддерживается (vGPU, часть драйверов
+// Windows), опрашивает --query-compute-apps раз в nvidiaProcsEvery.
+func (s *nvidiaSampler) streamProcesses(ctx context.Context) {
+	var pmon nvidiaPmon
+	handle := func(line string) {
+		if procs, ok := pmon.line(line); ok {
+			s.setProcesses(procs)
+		}
+	}
+	if s.keepStreaming(ctx, s.smi.pmonQuery, handle) {
+		return
+	}
+	for {
+		s.setProcesses(s.smi.computeApps())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(nvidiaProcsEvery):
+		}
+	}
+}
+
+// stream запускает nvidia-smi и передаёт каждую строку stdout в handle до завершения процесса.
+// Возвращает число строк и начало stderr.
+func (s *nvidiaSampler) stream(ctx context.Context, args []string, handle func(string)) (int, string, error) {
+	cmd := s.smi.command(ctx, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, "", err
+	}
+	var stderr headBuffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return 0, "", err
+	}
+	lines := 0
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		lines++
+		handle(line)
+	}
+	err = cmd.Wait()
+	return lines, stderr.String(), err
+}
+
+// headBuffer хранит первые nvidiaStderrMax байт и отбрасывает остальное.
+type headBuffer struct {
+	buf bytes.Buffer
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := nvidiaStderrMax - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *headBuffer) String() string { return b.buf.String() }
+
+func (s *nvidiaSampler) handleGPULine(line string) {
+	gpus := parseNvidiaGPUs(strings.NewReader(line))
+	if len(gpus) == 0 {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	for _, g := range gpus {
+		s.gpus[g.Index] = nvidiaGPUSample{info: g, at: now}
+	}
+	s.mu.Unlock()
+}
+
+func (s *nvidiaSampler) handlePCIeLine(line string) {
+	pcie := parseNvidiaDmonPCIe(strings.NewReader(line))
+	if len(pcie) == 0 {
+		return
+	}
+	s.mu.Lock()
+	for idx, t := range pcie {
+		s.pcie[idx] = t
+	}
+	s.mu.Unlock()
+}
+
+func (s *nvidiaSampler) sleep(d time.Duration) bool {
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNvidiaGPUs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []GPUInfo
+	}{
+		{
+			name: "basic",
+			in:   "0, 00000000:01:00.0, NVIDIA GeForce RTX 3060, 35, 2048, 12288, 49, 31.20, 30\n",
+			want: []GPUInfo{{Index: 0, Vendor: "nvidia", BusID: "00000000:01:00.0", Name: "NVIDIA GeForce RTX 3060",
+				UtilPercent: 35, MemoryUsedMB: 2048, MemoryTotalMB: 12288, TempC: 49, PowerW: 31.2, FanPercent: 30}},
+		},
+		{
+			name: "extended",
+			in:   "1, 00000000:02:00.0, Tesla T4, 90, 100, 15360, 70, 60.5, [N/A], 70.00, 1590, 1590, 5000, 12, 3, 0x0000000000000044\n",
+			want: []GPUInfo{{Index: 1, Vendor: "nvidia", BusID: "00000000:02:00.0", Name: "Tesla T4",
+				UtilPercent: 90, MemoryUsedMB: 100, MemoryTotalMB: 15360, TempC: 70, PowerW: 60.5, PowerLimitW: 70,
+				FreqMHz: 1590, MaxFreqMHz: 1590, MemoryFreqMHz: 5000, EncoderPercent: 12, DecoderPercent: 3,
+				ThrottleReasons: []string{"sw_power_cap", "hw_thermal_slowdown"}}},
+		},
+		{
+			name: "not supported fields",
+			in:   "0, 00000000:01:00.0, Quadro, [Not Supported], 10, 4096, 40, [N/A], [N/A]\n",
+			want: []GPUInfo{{Vendor: "nvidia", BusID: "00000000:01:00.0", Name: "Quadro", MemoryUsedMB: 10, MemoryTotalMB: 4096, TempC: 40}},
+		},
+		{
+			name: "error line is skipped",
+			in:   "Failed to initialize NVML: Driver/library version mismatch\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		if got := parseNvidiaGPUs(strings.NewReader(tt.in)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseNvidiaDmonPCIe(t *testing.T) {
+	in := "# gpu   rxpci   txpci\n# Idx    MB/s    MB/s\n    0      12       3\n    1       -       -\n"
+	want := map[int][2]float64{0: {12, 3}, 1: {0, 0}}
+	if got := parseNvidiaDmonPCIe(strings.NewReader(in)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseNvidiaComputeApps(t *testing.T) {
+	in := "4242, 00000000:01:00.0, python3, 1024\n17, 00000000:02:00.0, C:\\app\\game.exe, [N/A]\nNo running processes found\n"
+	want := []GPUProcess{
+		{PID: 4242, BusID: "00000000:01:00.0", Type: "compute", Name: "python3", UsedMemoryMB: 1024},
+		{PID: 17, BusID: "00000000:02:00.0", Type: "compute", Name: `C:\app\game.exe`},
+	}
+	if got := parseNvidiaComputeApps(strings.NewReader(in)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestNvidiaPmon(t *testing.T) {
+	xorg := GPUProcess{PID: 2183, GPUIndex: 0, Type: "graphics", Name: "Xorg", UsedMemoryMB: 77}
+	train := GPUProcess{PID: 4242, GPUIndex: 1, Type: "compute+graphics", Name: "python3 train.py", UsedMemoryMB: 1024}
+	type step struct {
+		line string
+		want []GPUProcess
+		done bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "time column",
+			steps: []step{
+				{line: "#Time        gpu         pid   type     fb   ccpm   command"},
+				{line: "#HH:MM:SS    Idx           #    C/G     MB     MB   name"},
+				{line: "12:00:01      0       2183     G     77      0   Xorg"},
+				{line: "12:00:01      1       4242   C+G   1024      0   python3 train.py"},
+				{line: "12:00:02      0       2183     G     77      0   Xorg", want: []GPUProcess{xorg, train}, done: true},
+				{line: "12:00:02      1          -     -      -      -   -"},
+				{line: "12:00:03      0          -     -      -      -   -", want: []GPUProcess{xorg}, done: true},
+				{line: "12:00:04      0          -     -      -      -   -", want: nil, done: true},
+			},
+		},
+		{
+			name: "no time column",
+			steps: []step{
+				{line: "# gpu         pid   type     fb   command"},
+				{line: "    0       2183     G     77   Xorg"},
+				{line: "    1       4242   C+G   1024   python3 train.py"},
+				{line: "    0       2183     G     77   Xorg", want: []GPUProcess{xorg, train}, done: true},
+			},
+		},
+		{
+			name: "rows before header are ignored",
+			steps: []step{
+				{line: "    0       2183     G     77   Xorg"},
+				{line: "# gpu         pid   type     fb   command"},
+				{line: "    0       2183     G     77   Xorg"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		var p nvidiaPmon
+		for i, s := range tt.steps {
+			got, done := p.line(s.line)
+			if done != s.done || !reflect.DeepEqual(got, s.want) {
+				t.Errorf("%s: line %d: got %+v, %v; want %+v, %v", tt.name, i, got, done, s.want, s.done)
+			}
+		}
+	}
+}
+
+func TestNvidiaFieldError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{`Field "clocks.max.sm" is not a valid field to query.`, true},
+		{`Invalid field: clocks_throttle_reasons.active`, true},
+		{"Failed to initialize NVML: Driver/library version mismatch", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := nvidiaFieldError(tt.stderr); got != tt.want {
+			t.Errorf("nvidiaFieldError(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// memoryTTL — сколько живёт прочитанная разбивка памяти процесса. smaps_rollup заставляет
+// ядро обойти все отображения процесса, поэтому читаем его не чаще раза в memoryTTL
+// и только когда он нужен (сортировка, страница с метриками, группы приложений, детали).
+const memoryTTL = 10 * time.Second
+
+// memoryUsage — память процесса без двойного учёта общих страниц, байты.
+type memoryUsage struct {
+	RSS     uint64
+	PSS     uint64 // доля процесса в общих страницах + личные страницы
+	USS     uint64 // только личные страницы: столько освободится при завершении
+	Shared  uint64 // общие страницы целиком
+	Swap    uint64
+	SwapPSS uint64
+}
+
+// memoryCache — разбивка памяти процессов, прочитанная по запросу.
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[int32]cachedMemory
+}
+
+type cachedMemory struct {
+	startTime int64
+	at        time.Time
+	usage     memoryUsage
+	ok        bool
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: make(map[int32]cachedMemory)}
+}
+
+// memorySupported сообщает, умеет ли платформа считать PSS/USS.
+func memorySupported() bool {
+	_, err := readMemoryUsage(1)
+	return !errors.Is(err, errors.ErrUnsupported)
+}
+
+// get возвращает разбивку памяти процесса pid, запущенного в startTime, перечитывая её
+// не чаще раза в memoryTTL. false — прочитать не удалось (нет прав, процесс завершился).
+func (mc *memoryCache) get(pid int32, startTime int64, now time.Time) (memoryUsage, bool) {
+	mc.mu.Lock()
+	e, ok := mc.entries[pid]
+	mc.mu.Unlock()
+	if ok && e.startTime == startTime && now.Sub(e.at) < memoryTTL {
+		return e.usage, e.ok
+	}
+	u, err := readMemoryUsage(pid)
+	e = cachedMemory{startTime: startTime, at: now, usage: u, ok: err == nil}
+	mc.mu.Lock()
+	mc.entries[pid] = e
+	mc.mu.Unlock()
+	return e.usage, e.ok
+}
+
+// prune выбрасывает давно не запрошенные записи (в том числе завершившихся процессов).
+func (mc *memoryCache) prune(now time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	for pid, e := range mc.entries {
+		if now.Sub(e.at) > 6*memoryTTL {
+			delete(mc.entries, pid)
+		}
+	}
+}
+
+// attach заполняет PSS, USS, общую память и swap в info.
+func (mc *memoryCache) attach(info *ProcessInfo, now time.Time) {
+	u, ok := mc.get(info.PID, info.StartTime, now)
+	if !ok {
+		return
+	}
+	info.PSSMB, info.USSMB = u.PSS/(1024*1024), u.USS/(1024*1024)
+	info.SharedMB, info.SwapMB = u.Shared/(1024*1024), u.Swap/(1024*1024)
+}
@@ -0,0 +1,56 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// readMemoryUsage читает /proc/<pid>/smaps_rollup (Linux 4.14+). Для чужих процессов
+// нужны те же права, что и для ptrace, иначе — ошибка доступа.
+func readMemoryUsage(pid int32) (memoryUsage, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/smaps_rollup")
+	if err != nil {
+		return memoryUsage{}, err
+	}
+	return parseSmapsRollup(data), nil
+}
+
+// parseSmapsRollup разбирает строки вида "Pss:  1234 kB".
+func parseSmapsRollup(data []byte) memoryUsage {
+	var u memoryUsage
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		key, rest, ok := bytes.Cut(sc.Bytes(), []byte(":"))
+		if !ok {
+			continue
+		}
+		fields := bytes.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		kb, err := strconv.ParseUint(string(fields[0]), 10, 64)
+		if err != nil {
+			continue
+		}
+		v := kb * 1024
+		switch string(key) {
+		case "Rss":
+			u.RSS = v
+		case "Pss":
+			u.PSS = v
+		case "Private_Clean", "Private_Dirty":
+			u.USS += v
+		case "Shared_Clean", "Shared_Dirty":
+			u.Shared += v
+		case "Swap":
+			u.Swap = v
+		case "SwapPss":
+			u.SwapPSS = v
+		}
+	}
+	return u
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package monitor
+
+import "errors"
+
+// readMemoryUsage — PSS и USS есть только в smaps_rollup Linux.
+func readMemoryUsage(int32) (memoryUsage, error) {
+	return memoryUsage{}, errors.ErrUnsupported
+}
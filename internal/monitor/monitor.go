@@ -13,7 +13,6 @@ import (
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/net"
-	"github.com/shirou/gopsutil/v3/process"
 )
 
 // Stats — снимок системных метрик для виджетов и API.
@@ -39,12 +38,18 @@ type Stats struct {
 	DiskTotalGB    uint64  `json:"disk_total_gb"`
 	DiskFreeGB     uint64  `json:"disk_free_gb,omitempty"`
 	DiskPath       string  `json:"disk_path,omitempty"`
-	// GPU
+	// GPU (агрегат по всем устройствам)
 	GPUPercent       float64 `json:"gpu_percent,omitempty"`
 	GPUName          string  `json:"gpu_name,omitempty"`
 	GPUTempC         int     `json:"gpu_temp_c,omitempty"`
 	GPUMemoryUsedMB  uint64  `json:"gpu_memory_used_mb,omitempty"`
 	GPUMemoryTotalMB uint64  `json:"gpu_memory_total_mb,omitempty"`
+	GPUVendor        string  `json:"gpu_vendor,omitempty"`       // nvidia / intel
+	GPUFreqMHz       int     `json:"gpu_freq_mhz,omitempty"`     // текущая частота
+	GPUMaxFreqMHz    int     `json:"gpu_max_freq_mhz,omitempty"` // максимальная частота
+	GPUEngines       map[string]float64 `json:"gpu_engines,omitempty"` // загрузка по движкам, %
+	// GPUs — все найденные GPU; плоские поля GPU* выше — агрегат по ним для совместимости.
+	GPUs []GPUInfo `json:"gpus,omitempty"`
 	// CPU температура (°C), если доступна (Linux: sensors; Windows: часто 0).
 	CPUTempC int `json:"cpu_temp_c,omitempty"`
 	// Система
@@ -55,6 +60,9 @@ type Stats struct {
 	KernelVersion string `json:"kernel_version,omitempty"`
 	UptimeSec    uint64 `json:"uptime_sec"`
 	ProcessCount int    `json:"process_count"`
+	ZombieCount  int    `json:"zombie_count"`
+	BlockedCount int    `json:"blocked_count"` // в непрерываемом сне (D)
+	StoppedCount int    `json:"stopped_count"`
 	// Сеть
 	NetBytesSent uint64 `json:"net_bytes_sent,omitempty"`
 	NetBytesRecv uint64 `json:"net_bytes_recv,omitempty"`
@@ -67,12 +75,59 @@ type Collector struct {
 	last   Stats
 	ticker *time.Ticker
 	stop   chan struct{}
+	// gpuProcs — процессы на GPU NVIDIA, procGPU — использование GPU по PID (под mu).
+	gpuProcs []GPUProcess
+	procGPU  map[int32]processGPU
+	// procList — снимок таблицы процессов с последнего тика (под mu).
+	procList []processSnapshot
+	// dns — кэш обратного DNS для адресов соединений.
+	dns *reverseDNS
+	// events — журнал запусков и завершений процессов.
+	events *processEventLog
+	// idents — кэш exe и cmdline для списка наблюдения.
+	idents *identityCache
+	// mem — PSS/USS процессов, читаются по запросу.
+	mem *memoryCache
+	// threads — прошлые замеры потоков для /api/processes/{pid}/threads.
+	threads *threadSampler
+	// Семплеры с состоянием между тиками; используются только из loop().
+	procs  *processTable
+	drm    *drmSampler
+	intel  *intelGPU
+	nvidia *nvidiaSampler
+}
+
+// CollectorConfig — настройки коллектора.
+type CollectorConfig struct {
+	Interval time.Duration
+	// NvidiaSMIPath — путь к nvidia-smi; пусто — искать в PATH.
+	// Позволяет подставить фейковый nvidia-smi для проверки без GPU.
+	NvidiaSMIPath string
 }
 
 // NewCollector создаёт коллектор и запускает фоновое обновление раз в interval.
 func NewCollector(interval time.Duration) *Collector {
-	c := &Collector{stop: make(chan struct{})}
-	c.ticker = time.NewTicker(interval)
+	return NewCollectorWithConfig(CollectorConfig{Interval: interval})
+}
+
+// NewCollectorWithConfig создаёт коллектор с настройками cfg и запускает фоновое обновление.
+func NewCollectorWithConfig(cfg CollectorConfig) *Collector {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	c := &Collector{
+		stop:    make(chan struct{}),
+		procs:   newProcessTable(),
+		dns:     newReverseDNS(),
+		events:  newProcessEventLog(),
+		idents:  newIdentityCache(),
+		mem:     newMemoryCache(),
+		threads: newThreadSampler(),
+		drm:     newDRMSampler(),
+		intel:   newIntelGPU(),
+		nvidia:  newNvidiaSampler(newNvidiaSMI(cfg.NvidiaSMIPath), cfg.Interval),
+	}
+	c.ticker = time.NewTicker(cfg.Interval)
 	go c.loop()
 	return c
 }
@@ -192,12 +247,20 @@ func (c *Collector) collect() {
 		uptimeSec = hi.Uptime
 	}
 
-	processCount := 0
-	if pids, err := process.Pids(); err == nil {
-		processCount = len(pids)
-	}
+	procList, procEvents, procErr := c.procs.refresh()
 
-	gpu := getGPUStats()
+	gpus, gpuProcs := c.nvidia.sample()
+	var drm []drmUsage
+	if c.drm.available() {
+		drm = c.drm.sample()
+	}
+	if c.intel.available() {
+		for _, g := range c.intel.sample(drm) {
+			g.Index = len(gpus)
+			gpus = append(gpus, g)
+		}
+	}
+	gpu := aggregateGPUs(gpus)
 
 	netSent := uint64(0)
 	netRecv := uint64(0)
@@ -207,6 +270,13 @@ func (c *Collector) collect() {
 	}
 
 	c.mu.Lock()
+	c.gpuProcs = gpuProcs
+	c.procGPU = mergeProcessGPU(gpuProcs, drm)
+	if procErr == nil {
+		c.procList = procList
+	}
+	processCount := len(c.procList)
+	stuck := countStuck(c.procList)
 	c.last = Stats{
 		CPUPercent:        cpuPct,
 		CPUModelName:      cpuModelName,
@@ -231,6 +301,11 @@ func (c *Collector) collect() {
 		GPUTempC:          gpu.TempC,
 		GPUMemoryUsedMB:   gpu.MemoryUsedMB,
 		GPUMemoryTotalMB:  gpu.MemoryTotalMB,
+		GPUVendor:         gpu.Vendor,
+		GPUFreqMHz:        gpu.FreqMHz,
+		GPUMaxFreqMHz:     gpu.MaxFreqMHz,
+		GPUEngines:        gpu.Engines,
+		GPUs:              gpus,
 		CPUTempC:          cpuTempC,
 		Hostname:          hostname,
 		Platform:          platform,
@@ -239,11 +314,15 @@ func (c *Collector) collect() {
 		KernelVersion:     kernelVersion,
 		UptimeSec:         uptimeSec,
 		ProcessCount:      processCount,
+		ZombieCount:       stuck.Zombie,
+		BlockedCount:      stuck.Blocked,
+		StoppedCount:      stuck.Stopped,
 		NetBytesSent:      netSent,
 		NetBytesRecv:      netRecv,
 		Timestamp:         time.Now().Unix(),
 	}
 	c.mu.Unlock()
+	c.events.add(procEvents)
 }
 
 // Get возвращает последний снимок метрик.
@@ -253,9 +332,17 @@ func (c *Collector) Get() Stats {
 	return c.last
 }
 
+// GPUProcesses возвращает процессы на GPU NVIDIA с последнего тика.
+func (c *Collector) GPUProcesses() []GPUProcess {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]GPUProcess(nil), c.gpuProcs...)
+}
+
 // Stop останавливает фоновое обновление. Вызывать при выходе из приложения.
 func (c *Collector) Stop() {
 	close(c.stop)
+	c.nvidia.stop()
 }
 
 // CollectOnce собирает метрики один раз (для API без фонового коллектора).
@@ -0,0 +1,247 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Дополнительные исходы для изменения приоритетов (см. SignalResult.Outcome).
+const (
+	OutcomeApplied         = "applied"
+	OutcomeInvalidArgument = "invalid_argument"
+)
+
+// Классы I/O-приоритета Linux (ioprio_set).
+const (
+	IOClassNone       = "none" // по умолчанию: уровень вычисляется из nice
+	IOClassRealtime   = "realtime"
+	IOClassBestEffort = "best-effort"
+	IOClassIdle       = "idle"
+)
+
+// ProcessPriority — текущие приоритеты процесса.
+type ProcessPriority struct {
+	Nice     int32  `json:"nice"`               // -20..19; на Windows — эквивалент класса приоритета
+	Affinity string `json:"affinity,omitempty"` // список CPU, например "0-3,6"
+	IOClass  string `json:"io_class,omitempty"` // только Linux
+	IOLevel  int    `json:"io_level,omitempty"` // 0 (высший) .. 7
+}
+
+// IOPriority возвращает I/O-приоритет в виде "best-effort/4" или "idle".
+func (p ProcessPriority) IOPriority() string {
+	switch p.IOClass {
+	case "":
+		return ""
+	case IOClassIdle:
+		return IOClassIdle
+	}
+	return fmt.Sprintf("%s/%d", p.IOClass, p.IOLevel)
+}
+
+// PriorityChange — что изменить. Пустые поля не трогаются.
+type PriorityChange struct {
+	Nice *int `json:"nice,omitempty"`
+	// Affinity — список CPU ("0-3,6") или битовая маска ("0xf").
+	Affinity string `json:"affinity,omitempty"`
+	IOClass  string `json:"io_class,omitempty"`
+	IOLevel  *int   `json:"io_level,omitempty"`
+}
+
+// Empty сообщает, что изменений не запрошено.
+func (ch PriorityChange) Empty() bool {
+	return ch.Nice == nil && ch.Affinity == "" && ch.IOClass == "" && ch.IOLevel == nil
+}
+
+// Validate проверяет диапазоны и формат запрошенных изменений.
+func (ch PriorityChange) Validate() error {
+	if ch.Empty() {
+		return errors.New("nothing to change: set nice, affinity, io_class or io_level")
+	}
+	if ch.Nice != nil && (*ch.Nice < -20 || *ch.Nice > 19) {
+		return fmt.Errorf("nice %d out of range -20..19", *ch.Nice)
+	}
+	if ch.Affinity != "" {
+		if _, err := parseCPUList(ch.Affinity); err != nil {
+			return err
+		}
+	}
+	switch ch.IOClass {
+	case "", IOClassNone, IOClassRealtime, IOClassBestEffort, IOClassIdle:
+	default:
+		return fmt.Errorf("unknown io_class %q (none, realtime, best-effort, idle)", ch.IOClass)
+	}
+	if ch.IOLevel != nil && (*ch.IOLevel < 0 || *ch.IOLevel > 7) {
+		return fmt.Errorf("io_level %d out of range 0..7", *ch.IOLevel)
+	}
+	return nil
+}
+
+// PriorityResult — итог изменения приоритетов одного процесса.
+// Priority — значения после изменения (если процесс удалось прочитать).
+type PriorityResult struct {
+	PID      int32            `json:"pid"`
+	Outcome  string           `json:"outcome"`
+	Error    string           `json:"error,omitempty"`
+	Priority *ProcessPriority `json:"priority,omitempty"`
+}
+
+// OK сообщает, что все запрошенные изменения применены.
+func (r PriorityResult) OK() bool { return r.Outcome == OutcomeApplied }
+
+// SetProcessPriority меняет nice, привязку к CPU и I/O-приоритет процесса pid.
+// Изменения применяются по очереди; на первой ошибке остальные пропускаются.
+func SetProcessPriority(pid int32, ch PriorityChange) PriorityResult {
+	res := PriorityResult{PID: pid}
+	if err := ch.Validate(); err != nil {
+		res.Outcome, res.Error = OutcomeInvalidArgument, err.Error()
+		return res
+	}
+	if _, err := identify(pid); err != nil {
+		res.Outcome, res.Error = OutcomeNotFound, err.Error()
+		return res
+	}
+	if err := applyPriority(pid, ch); err != nil {
+		res.Outcome, res.Error = classifySignalError(err), err.Error()
+		if res.Outcome == OutcomePermissionDenied {
+			res.Error += " (raising priority or changing another user's process requires elevated privileges)"
+		}
+	} else {
+		res.Outcome = OutcomeApplied
+	}
+	if cur, err := readPriority(pid); err == nil {
+		res.Priority = &cur
+	}
+	return res
+}
+
+func applyPriority(pid int32, ch PriorityChange) error {
+	if ch.Nice != nil {
+		if err := setNice(pid, *ch.Nice); err != nil {
+			return fmt.Errorf("set nice %d: %w", *ch.Nice, err)
+		}
+	}
+	if ch.Affinity != "" {
+		cpus, _ := parseCPUList(ch.Affinity)
+		if err := setAffinity(pid, cpus); err != nil {
+			return fmt.Errorf("set affinity %s: %w", formatCPUList(cpus), err)
+		}
+	}
+	if ch.IOClass != "" || ch.IOLevel != nil {
+		class, level := ch.IOClass, 4
+		if class == "" {
+			class = IOClassBestEffort
+		}
+		if ch.IOLevel != nil {
+			level = *ch.IOLevel
+		}
+		if err := setIOPriority(pid, class, level); err != nil {
+			return fmt.Errorf("set io priority %s/%d: %w", class, level, err)
+		}
+	}
+	return nil
+}
+
+// SetProcessTreePriority применяет изменения к процессу pid и всем его потомкам.
+func SetProcessTreePriority(pid int32, ch PriorityChange) ([]PriorityResult, error) {
+	if err := ch.Validate(); err != nil {
+		return nil, err
+	}
+	pids, err := ProcessTreePIDs(pid)
+	if err != nil {
+		return nil, err
+	}
+	return SetProcessListPriority(pids, ch), nil
+}
+
+// SetProcessListPriority применяет изменения к процессам pids — результату ProcessTreePIDs,
+// уже проверенному защитой процессов. Сначала корень (последний в pids): он важнее
+// потомков, если права есть не на всё.
+func SetProcessListPriority(pids []int32, ch PriorityChange) []PriorityResult {
+	out := make([]PriorityResult, 0, len(pids))
+	for i := len(pids) - 1; i >= 0; i-- {
+		out = append(out, SetProcessPriority(pids[i], ch))
+	}
+	return out
+}
+
+// Больше CPU, чем помещается в cpu_set_t ядра Linux, задать нельзя.
+const maxCPUs = 1024
+
+// parseCPUList разбирает "0-3,6" или маску "0xf" в отсортированный список номеров CPU.
+func parseCPUList(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	set := make(map[int]bool)
+	if hex, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		mask, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil || mask == 0 {
+			return nil, fmt.Errorf("invalid affinity mask %q", s)
+		}
+		for mask != 0 {
+			cpu := bits.TrailingZeros64(mask)
+			set[cpu] = true
+			mask &^= 1 << cpu
+		}
+	} else {
+		for _, part := range strings.Split(s, ",") {
+			lo, hi, isRange := strings.Cut(strings.TrimSpace(part), "-")
+			a, err1 := strconv.Atoi(lo)
+			b, err2 := a, error(nil)
+			if isRange {
+				b, err2 = strconv.Atoi(hi)
+			}
+			if err1 != nil || err2 != nil || a < 0 || b < a || b >= maxCPUs {
+				return nil, fmt.Errorf("invalid cpu list %q", s)
+			}
+			for cpu := a; cpu <= b; cpu++ {
+				set[cpu] = true
+			}
+		}
+	}
+	cpus := make([]int, 0, len(set))
+	for cpu := range set {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// formatCPUList сворачивает отсортированный список CPU в "0-3,6".
+func formatCPUList(cpus []int) string {
+	var b strings.Builder
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(cpus[i]))
+		if j > i {
+			b.WriteString("-" + strconv.Itoa(cpus[j]))
+		}
+		i = j + 1
+	}
+	return b.String()
+}
+
+// attachPriority дополняет список процессов текущими nice, привязкой и I/O-приоритетом.
+func attachPriority(list []ProcessInfo) {
+	for i := range list {
+		fillPriority(&list[i])
+	}
+}
+
+func fillPriority(info *ProcessInfo) {
+	cur, err := readPriority(info.PID)
+	if err != nil {
+		return
+	}
+	info.Nice = &cur.Nice
+	info.Affinity = cur.Affinity
+	info.IOPriority = cur.IOPriority()
+}
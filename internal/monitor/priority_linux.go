@@ -0,0 +1,136 @@
+//go:build linux
+
+package monitor
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// processNice возвращает nice процесса (-20..19).
+// gopsutil отдаёт сырой результат getpriority, который в Linux равен 20-nice.
+func processNice(pid int32) (int32, error) {
+	v, err := unix.Getpriority(unix.PRIO_PROCESS, int(pid))
+	if err != nil {
+		return 0, err
+	}
+	return int32(20 - v), nil
+}
+
+// readAffinity возвращает привязку потока tid к CPU списком ("0-3"); пусто — не прочитать.
+func readAffinity(tid int) string {
+	var set unix.CPUSet
+	if unix.SchedGetaffinity(tid, &set) != nil {
+		return ""
+	}
+	var cpus []int
+	for cpu := 0; cpu < maxCPUs && len(cpus) < set.Count(); cpu++ {
+		if set.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return formatCPUList(cpus)
+}
+
+// ioprio: класс в старших битах, уровень в младших 13 (linux/ioprio.h).
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+var ioprioClasses = []string{IOClassNone, IOClassRealtime, IOClassBestEffort, IOClassIdle}
+
+func readPriority(pid int32) (ProcessPriority, error) {
+	nice, err := processNice(pid)
+	if err != nil {
+		return ProcessPriority{}, err
+	}
+	cur := ProcessPriority{Nice: nice}
+	cur.Affinity = readAffinity(int(pid))
+	v, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno == 0 {
+		class := int(v >> ioprioClassShift)
+		if class < len(ioprioClasses) {
+			cur.IOClass = ioprioClasses[class]
+			cur.IOLevel = int(v & (1<<ioprioClassShift - 1))
+		}
+		switch class {
+		case 0:
+			// Без явного класса ядро берёт уровень best-effort из nice.
+			cur.IOLevel = int(nice+20) / 5
+		case 3:
+			cur.IOLevel = 0 // у idle уровней нет
+		}
+	}
+	return cur, nil
+}
+
+// nice, привязка и ioprio в Linux задаются для потока — применяем ко всем потокам процесса,
+// как renice и taskset -a.
+func forEachThread(pid int32, fn func(tid int) error) error {
+	entries, err := os.ReadDir("/proc/" + strconv.Itoa(int(pid)) + "/task")
+	if err != nil {
+		return fn(int(pid))
+	}
+	var firstErr error
+	applied := 0
+	for _, e := range entries {
+		tid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if err := fn(tid); err != nil {
+			// Поток успел завершиться — не ошибка.
+			if errors.Is(err, unix.ESRCH) {
+				continue
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		applied++
+	}
+	if applied == 0 && firstErr == nil {
+		return unix.ESRCH
+	}
+	return firstErr
+}
+
+func setNice(pid int32, nice int) error {
+	return forEachThread(pid, func(tid int) error {
+		return unix.Setpriority(unix.PRIO_PROCESS, tid, nice)
+	})
+}
+
+func setAffinity(pid int32, cpus []int) error {
+	var set unix.CPUSet
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+	return forEachThread(pid, func(tid int) error {
+		return unix.SchedSetaffinity(tid, &set)
+	})
+}
+
+func setIOPriority(pid int32, class string, level int) error {
+	classNum := 0
+	for i, name := range ioprioClasses {
+		if name == class {
+			classNum = i
+		}
+	}
+	prio := classNum<<ioprioClassShift | level
+	if classNum == 0 {
+		prio = 0
+	}
+	return forEachThread(pid, func(tid int) error {
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(prio)); errno != 0 {
+			return errno
+		}
+		return nil
+	})
+}
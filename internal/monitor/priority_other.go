@@ -0,0 +1,36 @@
+//go:build !linux && !windows
+
+package monitor
+
+import (
+	"errors"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/unix"
+)
+
+// processNice возвращает nice процесса.
+func processNice(pid int32) (int32, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return 0, err
+	}
+	return p.Nice()
+}
+
+// Привязка к CPU и I/O-приоритет доступны только в Linux и (привязка) Windows.
+func readPriority(pid int32) (ProcessPriority, error) {
+	nice, err := processNice(pid)
+	if err != nil {
+		return ProcessPriority{}, err
+	}
+	return ProcessPriority{Nice: nice}, nil
+}
+
+func setNice(pid int32, nice int) error {
+	return unix.Setpriority(unix.PRIO_PROCESS, int(pid), nice)
+}
+
+func setAffinity(int32, []int) error { return errors.ErrUnsupported }
+
+func setIOPriority(int32, string, int) error { return errors.ErrUnsupported }
@@ -0,0 +1,114 @@
+//go:build windows
+
+package monitor
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Классы приоритета Windows и соответствующие им значения nice (как в psutil/htop).
+var priorityClasses = []struct {
+	class uint32
+	nice  int32
+}{
+	{windows.REALTIME_PRIORITY_CLASS, -20},
+	{windows.HIGH_PRIORITY_CLASS, -10},
+	{windows.ABOVE_NORMAL_PRIORITY_CLASS, -5},
+	{windows.NORMAL_PRIORITY_CLASS, 0},
+	{windows.BELOW_NORMAL_PRIORITY_CLASS, 10},
+	{windows.IDLE_PRIORITY_CLASS, 19},
+}
+
+var (
+	procGetProcessAffinityMask = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetProcessAffinityMask")
+	procSetProcessAffinityMask = windows.NewLazySystemDLL("kernel32.dll").NewProc("SetProcessAffinityMask")
+)
+
+func openProcess(pid int32, access uint32) (windows.Handle, error) {
+	return windows.OpenProcess(access, false, uint32(pid))
+}
+
+// processNice возвращает класс приоритета процесса, переведённый в шкалу nice.
+func processNice(pid int32) (int32, error) {
+	h, err := openProcess(pid, windows.PROCESS_QUERY_LIMITED_INFORMATION)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(h)
+	class, err := windows.GetPriorityClass(h)
+	if err != nil {
+		return 0, err
+	}
+	for _, pc := range priorityClasses {
+		if pc.class == class {
+			return pc.nice, nil
+		}
+	}
+	return 0, nil
+}
+
+func readPriority(pid int32) (ProcessPriority, error) {
+	nice, err := processNice(pid)
+	if err != nil {
+		return ProcessPriority{}, err
+	}
+	cur := ProcessPriority{Nice: nice}
+	h, err := openProcess(pid, windows.PROCESS_QUERY_LIMITED_INFORMATION)
+	if err != nil {
+		return cur, nil
+	}
+	defer windows.CloseHandle(h)
+	var procMask, sysMask uintptr
+	if r, _, _ := procGetProcessAffinityMask.Call(uintptr(h), uintptr(unsafe.Pointer(&procMask)), uintptr(unsafe.Pointer(&sysMask))); r != 0 {
+		var cpus []int
+		for cpu := 0; procMask != 0; cpu++ {
+			if procMask&1 != 0 {
+				cpus = append(cpus, cpu)
+			}
+			procMask >>= 1
+		}
+		cur.Affinity = formatCPUList(cpus)
+	}
+	return cur, nil
+}
+
+// setNice выбирает ближайший класс приоритета, не выше запрошенного.
+func setNice(pid int32, nice int) error {
+	class := uint32(windows.IDLE_PRIORITY_CLASS)
+	for _, pc := range priorityClasses {
+		if int(pc.nice) >= nice {
+			class = pc.class
+			break
+		}
+	}
+	h, err := openProcess(pid, windows.PROCESS_SET_INFORMATION)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+	return windows.SetPriorityClass(h, class)
+}
+
+func setAffinity(pid int32, cpus []int) error {
+	var mask uintptr
+	for _, cpu := range cpus {
+		if cpu >= int(unsafe.Sizeof(mask))*8 {
+			return errors.New("affinity above 64 CPUs requires processor groups")
+		}
+		mask |= 1 << cpu
+	}
+	h, err := openProcess(pid, windows.PROCESS_SET_INFORMATION|windows.PROCESS_QUERY_LIMITED_INFORMATION)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+	if r, _, err := procSetProcessAffinityMask.Call(uintptr(h), mask); r == 0 {
+		return err
+	}
+	return nil
+}
+
+func setIOPriority(int32, string, int) error { return errors.ErrUnsupported }
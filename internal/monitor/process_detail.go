@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ErrProcessNotFound — процесса с таким PID нет.
+var ErrProcessNotFound = errors.New("process not found")
+
+// ProcessDetail — полная информация о процессе для /api/processes/{pid}.
+// Поля, которые не удалось прочитать (нет прав, не поддерживается ОС), остаются пустыми.
+type ProcessDetail struct {
+	PID         int32               `json:"pid"`
+	PPID        int32               `json:"ppid"`
+	Name        string              `json:"name"`
+	Exe         string              `json:"exe,omitempty"`
+	Cmdline     string              `json:"cmdline,omitempty"`
+	Args        []string            `json:"args,omitempty"`
+	Cwd         string              `json:"cwd,omitempty"`
+	Username    string              `json:"username,omitempty"`
+	UIDs        []int32             `json:"uids,omitempty"`
+	Status      string              `json:"status,omitempty"`
+	StartTime   int64               `json:"start_time,omitempty"` // unix, секунды
+	ElapsedSec  int64               `json:"elapsed_sec,omitempty"`
+	NumThreads  int32               `json:"num_threads,omitempty"`
+	Nice        int32               `json:"nice"` // на Windows — эквивалент класса приоритета
+	OpenFiles   int32               `json:"open_files,omitempty"`
+	Rlimits     []ProcessRlimit     `json:"rlimits,omitempty"`
+	CPUTimes    *ProcessCPUTimes    `json:"cpu_times,omitempty"`
+	Memory      *ProcessMemory      `json:"memory,omitempty"`
+	Connections []ProcessConnection `json:"connections,omitempty"`
+	// Environ заполняется только по явному запросу; значения секретов заменены на "***".
+	Environ []string `json:"environ,omitempty"`
+}
+
+// ProcessRlimit — лимит ресурса; -1 означает "unlimited".
+type ProcessRlimit struct {
+	Resource string `json:"resource"`
+	Soft     int64  `json:"soft"`
+	Hard     int64  `json:"hard"`
+}
+
+// ProcessCPUTimes — накопленное процессорное время, секунды.
+type ProcessCPUTimes struct {
+	User   float64 `json:"user"`
+	System float64 `json:"system"`
+	IOWait float64 `json:"iowait,omitempty"`
+}
+
+// ProcessMemory — память процесса, байты.
+type ProcessMemory struct {
+	RSS  uint64 `json:"rss"`
+	VMS  uint64 `json:"vms"`
+	HWM  uint64 `json:"hwm,omitempty"`
+	Data uint64 `json:"data,omitempty"`
+	Swap uint64 `json:"swap,omitempty"`
+	// Из smaps_rollup (Linux): PSS делит общие страницы между процессами, USS — только личные.
+	PSS     uint64 `json:"pss,omitempty"`
+	USS     uint64 `json:"uss,omitempty"`
+	Shared  uint64 `json:"shared,omitempty"`
+	SwapPSS uint64 `json:"swap_pss,omitempty"`
+}
+
+// ProcessConnection — сокет процесса.
+type ProcessConnection struct {
+	FD         uint32 `json:"fd,omitempty"`
+	Protocol   string `json:"protocol"` // tcp / tcp6 / udp / udp6 / unix
+	LocalAddr  string `json:"local_addr,omitempty"`
+	LocalPort  uint32 `json:"local_port,omitempty"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	RemotePort uint32 `json:"remote_port,omitempty"`
+	RemoteHost string `json:"remote_host,omitempty"` // обратный DNS удалённого адреса
+	Status     string `json:"status,omitempty"`
+}
+
+// DetailOptions — что дополнительно включить в ProcessDetail.
+type DetailOptions struct {
+	WithEnv bool
+	// SecretPatterns — регулярные выражения для имён переменных, значения которых скрываются.
+	SecretPatterns []string
+}
+
+// Имена RLIMIT_* в порядке номеров ресурсов Linux.
+var rlimitNames = []string{
+	"cpu", "fsize", "data", "stack", "core", "rss", "nproc", "nofile",
+	"memlock", "as", "locks", "sigpending", "msgqueue", "nice", "rtprio", "rttime",
+}
+
+// GetProcessDetail собирает подробную информацию о процессе pid.
+func GetProcessDetail(pid int32, opts DetailOptions) (*ProcessDetail, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, ErrProcessNotFound
+	}
+	d := &ProcessDetail{PID: pid}
+	d.Name, _ = p.Name()
+	d.PPID, _ = p.Ppid()
+	d.Exe, _ = p.Exe()
+	d.Cmdline, _ = p.Cmdline()
+	d.Args, _ = p.CmdlineSlice()
+	d.Cwd, _ = p.Cwd()
+	d.Username, _ = p.Username()
+	d.UIDs, _ = p.Uids()
+	if status, err := p.Status(); err == nil && len(status) > 0 {
+		d.Status = status[0]
+	}
+	if ms, err := p.CreateTime(); err == nil && ms > 0 {
+		d.StartTime = ms / 1000
+		d.ElapsedSec = int64(time.Since(time.UnixMilli(ms)).Seconds())
+	}
+	d.NumThreads, _ = p.NumThreads()
+	d.Nice, _ = processNice(pid)
+	d.OpenFiles, _ = p.NumFDs()
+	if limits, err := p.Rlimit(); err == nil {
+		for _, l := range limits {
+			name := "unknown"
+			if l.Resource >= 0 && int(l.Resource) < len(rlimitNames) {
+				name = rlimitNames[l.Resource]
+			}
+			d.Rlimits = append(d.Rlimits, ProcessRlimit{Resource: name, Soft: rlimitValue(l.Soft), Hard: rlimitValue(l.Hard)})
+		}
+	}
+	if t, err := p.Times(); err == nil && t != nil {
+		d.CPUTimes = &ProcessCPUTimes{User: t.User, System: t.System, IOWait: t.Iowait}
+	}
+	if m, err := p.MemoryInfo(); err == nil && m != nil {
+		d.Memory = &ProcessMemory{RSS: m.RSS, VMS: m.VMS, HWM: m.HWM, Data: m.Data, Swap: m.Swap}
+		if u, err := readMemoryUsage(pid); err == nil {
+			d.Memory.PSS, d.Memory.USS, d.Memory.Shared, d.Memory.SwapPSS = u.PSS, u.USS, u.Shared, u.SwapPSS
+		}
+	}
+	if conns, err := net.ConnectionsPid("all", pid); err == nil {
+		for _, c := range conns {
+			d.Connections = append(d.Connections, toProcessConnection(c))
+		}
+	}
+	if opts.WithEnv {
+		if env, err := p.Environ(); err == nil {
+			d.Environ = redactEnviron(env, opts.SecretPatterns)
+		}
+	}
+	if d.Name == "" && d.Exe == "" && d.StartTime == 0 {
+		// Процесс завершился между NewProcess и чтением полей.
+		if running, _ := p.IsRunning(); !running {
+			return nil, ErrProcessNotFound
+		}
+	}
+	return d, nil
+}
+
+func rlimitValue(v uint64) int64 {
+	if v > uint64(1<<63-1) {
+		return -1
+	}
+	return int64(v)
+}
+
+func toProcessConnection(c net.ConnectionStat) ProcessConnection {
+	return ProcessConnection{
+		FD:         c.Fd,
+		Protocol:   connProtocol(c.Family, c.Type),
+		LocalAddr:  c.Laddr.IP,
+		LocalPort:  c.Laddr.Port,
+		RemoteAddr: c.Raddr.IP,
+		RemotePort: c.Raddr.Port,
+		Status:     c.Status,
+	}
+}
+
+func connProtocol(family, typ uint32) string {
+	proto := "tcp"
+	if typ == syscall.SOCK_DGRAM {
+		proto = "udp"
+	}
+	switch family {
+	case syscall.AF_INET6:
+		return proto + "6"
+	case syscall.AF_UNIX:
+		return "unix"
+	}
+	return proto
+}
+
+// redactEnviron заменяет значения переменных, имя которых совпало с одним из patterns, на "***".
+// Некорректные выражения пропускаются (они отсекаются при сохранении настроек).
+func redactEnviron(env []string, patterns []string) []string {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if re, err := regexp.Compile(p); err == nil {
+			res = append(res, re)
+		}
+	}
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		if kv == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(kv, "=")
+		for _, re := range res {
+			if re.MatchString(name) {
+				kv = name + "=***"
+				break
+			}
+		}
+		out = append(out, kv)
+	}
+	return out
+}
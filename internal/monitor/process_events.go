@@ -0,0 +1,173 @@
+package monitor
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Размер журнала событий процессов; старые события вытесняются.
+const processEventLogSize = 5000
+
+// Типы ProcessEvent.Type.
+const (
+	ProcessEventStart = "start"
+	ProcessEventExit  = "exit"
+)
+
+// ProcessEvent — запуск или завершение процесса, замеченное между тиками коллектора.
+// Процессы, прожившие меньше одного интервала, между тиками не видны.
+type ProcessEvent struct {
+	ID         uint64 `json:"id"`
+	Type       string `json:"type"`
+	Time       int64  `json:"time"` // unix, мс — когда событие замечено
+	PID        int32  `json:"pid"`
+	PPID       int32  `json:"ppid"`
+	ParentName string `json:"parent_name,omitempty"`
+	Name       string `json:"name"`
+	Cmdline    string `json:"cmdline,omitempty"`
+	Username   string `json:"username,omitempty"`
+	StartTime  int64  `json:"start_time,omitempty"` // unix, секунды
+	// Для exit: время жизни (с точностью до интервала) и пики за время наблюдения.
+	LifetimeSec    float64 `json:"lifetime_sec,omitempty"`
+	PeakCPUPercent float64 `json:"peak_cpu_percent,omitempty"`
+	PeakRSSMB      uint64  `json:"peak_rss_mb,omitempty"`
+}
+
+// ProcessEventFilter — выборка из журнала событий.
+type ProcessEventFilter struct {
+	AfterID uint64 // только события с ID больше
+	Since   time.Time
+	Type    string // start / exit; пусто — все
+	Name    string // подстрока имени, без учёта регистра
+	Limit   int    // последние Limit событий; 0 — все подходящие
+}
+
+// ProcessEventSummary — события, сгруппированные по имени процесса.
+type ProcessEventSummary struct {
+	Name           string   `json:"name"`
+	Starts         int      `json:"starts"`
+	Exits          int      `json:"exits"`
+	PeakCPUPercent float64  `json:"peak_cpu_percent,omitempty"`
+	PeakRSSMB      uint64   `json:"peak_rss_mb,omitempty"`
+	Parents        []string `json:"parents,omitempty"`
+	FirstTime      int64    `json:"first_time"`
+	LastTime       int64    `json:"last_time"`
+}
+
+// processEventLog — кольцевой журнал событий с рассылкой подписчикам.
+type processEventLog struct {
+	mu     sync.Mutex
+	events []ProcessEvent
+	nextID uint64
+	subs   map[chan []ProcessEvent]struct{}
+}
+
+func newProcessEventLog() *processEventLog {
+	return &processEventLog{nextID: 1, subs: make(map[chan []ProcessEvent]struct{})}
+}
+
+// add присваивает событиям ID, сохраняет их и рассылает подписчикам одной пачкой.
+// Медленный подписчик пропускает пачку, а не тормозит коллектор.
+func (l *processEventLog) add(batch []ProcessEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range batch {
+		batch[i].ID = l.nextID
+		l.nextID++
+	}
+	l.events = append(l.events, batch...)
+	if over := len(l.events) - processEventLogSize; over > 0 {
+		l.events = append(l.events[:0:0], l.events[over:]...)
+	}
+	for ch := range l.subs {
+		select {
+		case ch <- batch:
+		default:
+		}
+	}
+}
+
+func (l *processEventLog) list(f ProcessEventFilter) []ProcessEvent {
+	name := strings.ToLower(f.Name)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]ProcessEvent, 0)
+	for _, e := range l.events {
+		if e.ID <= f.AfterID ||
+			(!f.Since.IsZero() && e.Time < f.Since.UnixMilli()) ||
+			(f.Type != "" && e.Type != f.Type) ||
+			(name != "" && !strings.Contains(strings.ToLower(e.Name), name)) {
+			continue
+		}
+		out = append(out, e)
+	}
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[len(out)-f.Limit:]
+	}
+	return out
+}
+
+func (l *processEventLog) subscribe() (<-chan []ProcessEvent, func()) {
+	ch := make(chan []ProcessEvent, 16)
+	l.mu.Lock()
+	l.subs[ch] = struct{}{}
+	l.mu.Unlock()
+	return ch, func() {
+		l.mu.Lock()
+		delete(l.subs, ch)
+		l.mu.Unlock()
+	}
+}
+
+// ProcessEvents возвращает события запуска и завершения процессов из журнала (по возрастанию ID).
+func (c *Collector) ProcessEvents(f ProcessEventFilter) []ProcessEvent {
+	return c.events.list(f)
+}
+
+// SubscribeProcessEvents подписывает на новые события: каждый тик с изменениями приходит одной пачкой.
+// Вызовите cancel, когда события больше не нужны.
+func (c *Collector) SubscribeProcessEvents() (events <-chan []ProcessEvent, cancel func()) {
+	return c.events.subscribe()
+}
+
+// SummarizeProcessEvents группирует события по имени процесса, самые частые — первыми.
+func SummarizeProcessEvents(events []ProcessEvent) []ProcessEventSummary {
+	byName := make(map[string]*ProcessEventSummary)
+	parents := make(map[string]map[string]bool)
+	for _, e := range events {
+		s, ok := byName[e.Name]
+		if !ok {
+			s = &ProcessEventSummary{Name: e.Name, FirstTime: e.Time}
+			byName[e.Name] = s
+			parents[e.Name] = make(map[string]bool)
+		}
+		if e.Type == ProcessEventStart {
+			s.Starts++
+		} else {
+			s.Exits++
+		}
+		s.PeakCPUPercent = max(s.PeakCPUPercent, e.PeakCPUPercent)
+		s.PeakRSSMB = max(s.PeakRSSMB, e.PeakRSSMB)
+		s.LastTime = e.Time
+		if e.ParentName != "" && !parents[e.Name][e.ParentName] {
+			parents[e.Name][e.ParentName] = true
+			s.Parents = append(s.Parents, e.ParentName)
+		}
+	}
+	out := make([]ProcessEventSummary, 0, len(byName))
+	for _, s := range byName {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Starts+out[i].Exits != out[j].Starts+out[j].Exits {
+			return out[i].Starts+out[i].Exits > out[j].Starts+out[j].Exits
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
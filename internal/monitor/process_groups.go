@@ -0,0 +1,216 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Способы группировки ProcessQuery.Group.
+const (
+	GroupApp     = "app"     // по исполняемому файлу
+	GroupPGID    = "pgid"    // по группе процессов (POSIX)
+	GroupSession = "session" // по сессии (POSIX)
+)
+
+// ProcessGroup — процессы одного приложения (группы, сессии) одной строкой с суммами.
+// PSSMB, в отличие от суммы RSS, не считает общие библиотеки несколько раз; для app он
+// заполняется всегда, для остальных группировок — при сортировке по памяти (только Linux).
+type ProcessGroup struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Exe  string `json:"exe,omitempty"`
+	// LeaderPID — лидер группы или сессии; для app — самый старый процесс приложения.
+	LeaderPID        int32         `json:"leader_pid"`
+	Count            int           `json:"count"`
+	CPUPercent       float64       `json:"cpu_percent"`
+	RSSMB            uint64        `json:"rss_mb"`
+	PSSMB            uint64        `json:"pss_mb,omitempty"`
+	USSMB            uint64        `json:"uss_mb,omitempty"`
+	SwapMB           uint64        `json:"swap_mb,omitempty"`
+	DiskReadBps      float64       `json:"disk_read_bps,omitempty"`
+	DiskWriteBps     float64       `json:"disk_write_bps,omitempty"`
+	GPUPercent       float64       `json:"gpu_percent,omitempty"`
+	GPUMemoryMB      uint64        `json:"gpu_memory_mb,omitempty"`
+	ConnectionsCount int           `json:"connections_count,omitempty"`
+	PIDs             []int32       `json:"pids"`
+	Members          []ProcessInfo `json:"members,omitempty"` // только с WithMembers
+}
+
+// ProcessGroupPage — страница результата ListProcessGroups.
+type ProcessGroupPage struct {
+	Groups     []ProcessGroup `json:"groups"`
+	Total      int            `json:"total"`     // сколько групп
+	Processes  int            `json:"processes"` // сколько процессов прошло фильтры
+	Offset     int            `json:"offset"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// ListProcessGroups группирует процессы, прошедшие фильтры q, по q.Group и возвращает
+// страницу групп. Сортировка и курсор работают так же, как в ListProcesses, но по суммам
+// группы; дополнительно доступна сортировка по числу процессов (SortCount).
+func (c *Collector) ListProcessGroups(q ProcessQuery) (ProcessGroupPage, error) {
+	switch q.Group {
+	case GroupApp:
+	case GroupPGID, GroupSession:
+		if _, _, err := processGroupIDs(1); errors.Is(err, errors.ErrUnsupported) {
+			return ProcessGroupPage{}, fmt.Errorf("%w: group by %s is not supported on this platform", ErrInvalidQuery, q.Group)
+		}
+	default:
+		return ProcessGroupPage{}, fmt.Errorf("%w: group must be app, pgid or session", ErrInvalidQuery)
+	}
+	c.mu.RLock()
+	snaps, gpu := c.procList, c.procGPU
+	c.mu.RUnlock()
+	f, err := newProcessFilter(q, gpu)
+	if err != nil {
+		return ProcessGroupPage{}, err
+	}
+	if q.Group == GroupApp || f.needsMemory() {
+		f.mem = c.mem
+		defer c.mem.prune(f.now)
+	}
+
+	var idents map[int32]cachedIdentity
+	if q.Group == GroupApp {
+		idents = c.identities(snaps)
+	}
+	names := make(map[int32]string, len(snaps))
+	for _, s := range snaps {
+		names[s.info.PID] = s.info.Name
+	}
+
+	groups := make(map[string]*ProcessGroup)
+	// Память групп суммируется в байтах и переводится в МБ один раз: сумма округлённых
+	// МБ процессов занижала бы группу из сотни мелких процессов на десятки МБ.
+	bytes := make(map[string]*memoryUsage)
+	members := make(map[string][]ProcessInfo)
+	oldest := make(map[string]ProcessInfo) // самый старый процесс группы
+	matched := 0
+	for _, s := range snaps {
+		info, ok := f.match(s)
+		if !ok {
+			continue
+		}
+		var key, exe string
+		var leader int32
+		switch q.Group {
+		case GroupApp:
+			exe = idents[info.PID].exe
+			key = exe
+			if key == "" {
+				// exe не читается (нет прав, поток ядра) — группируем по имени.
+				key = "name:" + info.Name
+			}
+		default:
+			pgid, sid, err := processGroupIDs(info.PID)
+			if err != nil {
+				continue // процесс завершился
+			}
+			leader = pgid
+			if q.Group == GroupSession {
+				leader = sid
+			}
+			key = strconv.Itoa(int(leader))
+		}
+		matched++
+		g, ok := groups[key]
+		if !ok {
+			g = &ProcessGroup{Key: key, Exe: exe, LeaderPID: leader}
+			groups[key] = g
+			bytes[key] = &memoryUsage{}
+		}
+		b := bytes[key]
+		b.RSS += s.rss
+		if f.mem != nil {
+			if u, ok := f.mem.get(info.PID, info.StartTime, f.now); ok {
+				b.PSS += u.PSS
+				b.USS += u.USS
+				b.Swap += u.Swap
+			}
+		}
+		if o, ok := oldest[key]; !ok || info.StartTime < o.StartTime ||
+			(info.StartTime == o.StartTime && info.PID < o.PID) {
+			oldest[key] = info
+		}
+		g.Count++
+		g.CPUPercent += info.CPUPercent
+		g.DiskReadBps += info.DiskReadBps
+		g.DiskWriteBps += info.DiskWriteBps
+		g.GPUPercent += info.GPUPercent
+		g.GPUMemoryMB += info.GPUMemoryMB
+		g.ConnectionsCount += info.ConnectionsCount
+		g.PIDs = append(g.PIDs, info.PID)
+		members[key] = append(members[key], info)
+	}
+
+	rows := make([]processRow, 0, len(groups))
+	byLeader := make(map[int32]*ProcessGroup, len(groups))
+	for key, g := range groups {
+		// Для app лидер — самый старый процесс приложения. Имя группы — имя лидера,
+		// а если лидера группы или сессии нет среди процессов — имя самого старого.
+		b := bytes[key]
+		g.RSSMB, g.PSSMB = b.RSS/(1024*1024), b.PSS/(1024*1024)
+		g.USSMB, g.SwapMB = b.USS/(1024*1024), b.Swap/(1024*1024)
+		o := oldest[key]
+		if q.Group == GroupApp {
+			g.LeaderPID = o.PID
+		}
+		g.Name = o.Name
+		if name, ok := names[g.LeaderPID]; ok {
+			g.Name = name
+		}
+		sort.Slice(g.PIDs, func(i, j int) bool { return g.PIDs[i] < g.PIDs[j] })
+		// Строка группы для сортировки и курсора: PID лидера уникален среди групп.
+		r := newProcessRow(ProcessInfo{
+			PID:              g.LeaderPID,
+			Name:             g.Name,
+			StartTime:        o.StartTime,
+			CPUPercent:       g.CPUPercent,
+			RSSMB:            g.RSSMB,
+			PSSMB:            g.PSSMB,
+			USSMB:            g.USSMB,
+			SwapMB:           g.SwapMB,
+			DiskReadBps:      g.DiskReadBps,
+			DiskWriteBps:     g.DiskWriteBps,
+			GPUPercent:       g.GPUPercent,
+			GPUMemoryMB:      g.GPUMemoryMB,
+			ConnectionsCount: g.ConnectionsCount,
+		}, f.q.Sort)
+		if f.q.Sort == SortCount {
+			r.num = float64(g.Count)
+		}
+		rows = append(rows, r)
+		byLeader[g.LeaderPID] = g
+	}
+	start, end, next := f.window(rows)
+	page := ProcessGroupPage{
+		Groups:     make([]ProcessGroup, 0, end-start),
+		Total:      len(rows),
+		Processes:  matched,
+		Offset:     start,
+		Limit:      f.q.Limit,
+		NextCursor: next,
+	}
+	desc := f.q.Order == "desc"
+	for _, r := range rows[start:end] {
+		g := *byLeader[r.info.PID]
+		if q.WithMembers {
+			list := members[g.Key]
+			mrows := make([]processRow, 0, len(list))
+			for _, info := range list {
+				mrows = append(mrows, newProcessRow(info, f.q.Sort))
+			}
+			sort.Slice(mrows, func(i, j int) bool { return mrows[i].before(mrows[j], desc) })
+			g.Members = make([]ProcessInfo, 0, len(mrows))
+			for _, mr := range mrows {
+				g.Members = append(g.Members, mr.info)
+			}
+			attachPriority(g.Members)
+		}
+		page.Groups = append(page.Groups, g)
+	}
+	return page, nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package monitor
+
+import "golang.org/x/sys/unix"
+
+// processGroupIDs возвращает группу процессов и сессию pid.
+func processGroupIDs(pid int32) (pgid, sid int32, err error) {
+	g, err := unix.Getpgid(int(pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	s, err := unix.Getsid(int(pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	return int32(g), int32(s), nil
+}
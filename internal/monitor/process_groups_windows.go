@@ -0,0 +1,10 @@
+//go:build windows
+
+package monitor
+
+import "errors"
+
+// На Windows групп процессов и сессий в смысле POSIX нет.
+func processGroupIDs(int32) (pgid, sid int32, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
@@ -0,0 +1,388 @@
+package monitor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/user"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ErrInvalidQuery — некорректные параметры ProcessQuery (регулярное выражение, курсор, ключ сортировки).
+var ErrInvalidQuery = errors.New("invalid process query")
+
+const (
+	defaultPageLimit = 200
+	maxPageLimit     = 1000
+)
+
+// Ключи сортировки ProcessQuery.Sort.
+const (
+	SortPID         = "pid"
+	SortName        = "name"
+	SortCPU         = "cpu"
+	SortRSS         = "rss"
+	SortConnections = "connections"
+	SortStartTime   = "start_time"
+	SortGPU         = "gpu"
+	SortGPUMemory   = "gpu_memory"
+	SortDiskRead    = "disk_read"
+	SortDiskWrite   = "disk_write"
+	SortDiskIO      = "disk_io" // чтение + запись
+	SortPSS         = "pss"     // PSS/USS/swap — только Linux
+	SortUSS         = "uss"
+	SortSwap        = "swap"
+	SortCount       = "count" // число процессов в группе, только для ListProcessGroups
+)
+
+// ProcessQuery — фильтры, сортировка и страница для ListProcesses.
+// Limit применяется после фильтрации и сортировки.
+type ProcessQuery struct {
+	Sort string // см. Sort*; по умолчанию pid
+	// Order — "asc" или "desc"; по умолчанию desc для числовых метрик и asc для pid и name.
+	Order string
+
+	Query    string // подстрока имени (без учёта регистра)
+	Name     string // регулярное выражение по имени
+	Cmdline  string // регулярное выражение по командной строке
+	User     string
+	Status   []string // running, sleep, stop, zombie, ...
+	MinCPU   float64
+	MinRSSMB uint64
+
+	// Cursor — значение NextCursor предыдущей страницы; если задан, Offset не используется.
+	Cursor string
+	Offset int
+	Limit  int
+
+	// WithMetrics добавляет на страницу число соединений и PSS/USS/swap (CPU% есть всегда).
+	WithMetrics bool
+
+	// Group — см. Group*; задаётся для ListProcessGroups.
+	Group string
+	// WithMembers добавляет к группам на странице их процессы.
+	WithMembers bool
+}
+
+// ProcessPage — страница результата ListProcesses.
+type ProcessPage struct {
+	Processes  []ProcessInfo `json:"processes"`
+	Total      int           `json:"total"` // сколько процессов прошло фильтры
+	Offset     int           `json:"offset"`
+	Limit      int           `json:"limit"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// processRow — процесс с ключом сортировки.
+type processRow struct {
+	info ProcessInfo
+	num  float64
+	str  string
+}
+
+// processCursor — позиция последнего элемента страницы в порядке сортировки.
+type processCursor struct {
+	Sort string  `json:"s"`
+	Desc bool    `json:"d"`
+	Num  float64 `json:"n,omitempty"`
+	Str  string  `json:"t,omitempty"`
+	PID  int32   `json:"p"`
+}
+
+func (q *ProcessQuery) normalize() error {
+	q.Sort = strings.ToLower(strings.TrimSpace(q.Sort))
+	switch q.Sort {
+	case "":
+		q.Sort = SortPID
+	case SortPID, SortName, SortCPU, SortRSS, SortConnections, SortStartTime, SortGPU, SortGPUMemory,
+		SortDiskRead, SortDiskWrite, SortDiskIO:
+	case "memory":
+		q.Sort = SortRSS
+	case SortPSS, SortUSS, SortSwap:
+		if !memorySupported() {
+			return fmt.Errorf("%w: sort by %s is not supported on this platform", ErrInvalidQuery, q.Sort)
+		}
+	case SortCount:
+		if q.Group == "" {
+			return fmt.Errorf("%w: sort by count requires group", ErrInvalidQuery)
+		}
+	default:
+		return fmt.Errorf("%w: unknown sort key %q", ErrInvalidQuery, q.Sort)
+	}
+	switch strings.ToLower(q.Order) {
+	case "":
+		if q.Sort == SortPID || q.Sort == SortName {
+			q.Order = "asc"
+		} else {
+			q.Order = "desc"
+		}
+	case "asc", "desc":
+		q.Order = strings.ToLower(q.Order)
+	default:
+		return fmt.Errorf("%w: order must be asc or desc", ErrInvalidQuery)
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultPageLimit
+	}
+	q.Limit = min(q.Limit, maxPageLimit)
+	q.Offset = max(q.Offset, 0)
+	return nil
+}
+
+func compileFilter(field, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidQuery, field, err)
+	}
+	return re, nil
+}
+
+// processFilter — разобранные фильтры ProcessQuery.
+type processFilter struct {
+	q      ProcessQuery
+	substr string
+	nameRe *regexp.Regexp
+	cmdRe  *regexp.Regexp
+	cursor *processCursor
+	conns  map[int32]int
+	gpu    map[int32]processGPU
+	// mem задан, если разбивка памяти нужна для каждого прошедшего фильтры процесса.
+	mem *memoryCache
+	now time.Time
+}
+
+func newProcessFilter(q ProcessQuery, gpu map[int32]processGPU) (*processFilter, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	f := &processFilter{q: q, gpu: gpu, substr: strings.ToLower(strings.TrimSpace(q.Query))}
+	var err error
+	if f.nameRe, err = compileFilter("name", q.Name); err != nil {
+		return nil, err
+	}
+	if f.cmdRe, err = compileFilter("cmdline", q.Cmdline); err != nil {
+		return nil, err
+	}
+	if q.Cursor != "" {
+		if f.cursor, err = decodeProcessCursor(q.Cursor, q.Sort, q.Order == "desc"); err != nil {
+			return nil, err
+		}
+	}
+	// Соединения считаем одним проходом по всем сокетам, а не ConnectionsPid на каждый процесс.
+	if q.Sort == SortConnections || q.WithMetrics {
+		f.conns = connectionsByPID()
+	}
+	f.now = time.Now()
+	return f, nil
+}
+
+// needsMemory — сортировка требует PSS/USS/swap каждого процесса.
+func (f *processFilter) needsMemory() bool {
+	switch f.q.Sort {
+	case SortPSS, SortUSS, SortSwap:
+		return true
+	}
+	return false
+}
+
+// match проверяет процесс по фильтрам и дополняет его метриками GPU и соединений.
+func (f *processFilter) match(s processSnapshot) (ProcessInfo, bool) {
+	info := s.info
+	q := f.q
+	if f.substr != "" && !strings.Contains(strings.ToLower(info.Name), f.substr) {
+		return info, false
+	}
+	if f.nameRe != nil && !f.nameRe.MatchString(info.Name) {
+		return info, false
+	}
+	if len(q.Status) > 0 && !containsFold(q.Status, info.Status) {
+		return info, false
+	}
+	if info.RSSMB < q.MinRSSMB || info.CPUPercent < q.MinCPU {
+		return info, false
+	}
+	if q.User != "" && !strings.EqualFold(info.Username, q.User) {
+		return info, false
+	}
+	if f.cmdRe != nil {
+		cmdline, _ := s.proc.Cmdline()
+		if !f.cmdRe.MatchString(cmdline) {
+			return info, false
+		}
+	}
+	if f.conns != nil {
+		info.ConnectionsCount = f.conns[info.PID]
+	}
+	if u, ok := f.gpu[info.PID]; ok {
+		info.GPUPercent, info.GPUMemoryMB, info.GPUEngines = u.Percent, u.MemoryMB, u.Engines
+	}
+	if f.mem != nil {
+		f.mem.attach(&info, f.now)
+	}
+	return info, true
+}
+
+// window сортирует строки и возвращает границы страницы, её смещение и курсор следующей.
+func (f *processFilter) window(rows []processRow) (start, end int, next string) {
+	desc := f.q.Order == "desc"
+	sort.Slice(rows, func(i, j int) bool { return rows[i].before(rows[j], desc) })
+	start = f.q.Offset
+	if f.cursor != nil {
+		at := processRow{info: ProcessInfo{PID: f.cursor.PID}, num: f.cursor.Num, str: f.cursor.Str}
+		start = sort.Search(len(rows), func(i int) bool { return at.before(rows[i], desc) })
+	}
+	start = min(start, len(rows))
+	end = min(start+f.q.Limit, len(rows))
+	if end < len(rows) {
+		last := rows[end-1]
+		next = encodeProcessCursor(processCursor{Sort: f.q.Sort, Desc: desc, Num: last.num, Str: last.str, PID: last.info.PID})
+	}
+	return start, end, next
+}
+
+// queryProcesses фильтрует, сортирует и режет на страницы снимок таблицы процессов.
+func queryProcesses(q ProcessQuery, snaps []processSnapshot, gpu map[int32]processGPU, mc *memoryCache) (ProcessPage, error) {
+	f, err := newProcessFilter(q, gpu)
+	if err != nil {
+		return ProcessPage{}, err
+	}
+	if f.needsMemory() {
+		f.mem = mc
+	}
+	defer mc.prune(f.now)
+	rows := make([]processRow, 0, len(snaps))
+	for _, s := range snaps {
+		if info, ok := f.match(s); ok {
+			rows = append(rows, newProcessRow(info, f.q.Sort))
+		}
+	}
+	start, end, next := f.window(rows)
+	page := ProcessPage{Total: len(rows), Limit: f.q.Limit, Offset: start, NextCursor: next}
+	page.Processes = make([]ProcessInfo, 0, end-start)
+	for _, r := range rows[start:end] {
+		if f.mem == nil && q.WithMetrics {
+			// Без сортировки по памяти читаем smaps только для страницы.
+			mc.attach(&r.info, f.now)
+		}
+		page.Processes = append(page.Processes, r.info)
+	}
+	attachPriority(page.Processes)
+	return page, nil
+}
+
+func newProcessRow(info ProcessInfo, by string) processRow {
+	r := processRow{info: info}
+	switch by {
+	case SortName:
+		r.str = strings.ToLower(info.Name)
+	case SortCPU:
+		r.num = info.CPUPercent
+	case SortRSS:
+		r.num = float64(info.RSSMB)
+	case SortConnections:
+		r.num = float64(info.ConnectionsCount)
+	case SortStartTime:
+		r.num = float64(info.StartTime)
+	case SortGPU:
+		r.num = info.GPUPercent
+	case SortGPUMemory:
+		r.num = float64(info.GPUMemoryMB)
+	case SortDiskRead:
+		r.num = info.DiskReadBps
+	case SortDiskWrite:
+		r.num = info.DiskWriteBps
+	case SortDiskIO:
+		r.num = info.DiskReadBps + info.DiskWriteBps
+	case SortPSS:
+		r.num = float64(info.PSSMB)
+	case SortUSS:
+		r.num = float64(info.USSMB)
+	case SortSwap:
+		r.num = float64(info.SwapMB)
+	}
+	return r
+}
+
+// before — порядок строк: по ключу (asc/desc), при равенстве — по PID по возрастанию,
+// чтобы порядок был полным и курсор однозначно указывал позицию.
+func (a processRow) before(b processRow, desc bool) bool {
+	if a.str != b.str {
+		return (a.str < b.str) != desc
+	}
+	if a.num != b.num {
+		return (a.num < b.num) != desc
+	}
+	return a.info.PID < b.info.PID
+}
+
+func encodeProcessCursor(c processCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeProcessCursor(s, sortKey string, desc bool) (*processCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	var c processCursor
+	if err != nil || json.Unmarshal(b, &c) != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if c.Sort != sortKey || c.Desc != desc {
+		return nil, fmt.Errorf("%w: cursor belongs to a different sort order", ErrInvalidQuery)
+	}
+	return &c, nil
+}
+
+// connectionsByPID считает сокеты каждого процесса.
+func connectionsByPID() map[int32]int {
+	out := make(map[int32]int)
+	list, err := net.Connections("all")
+	if err != nil {
+		return out
+	}
+	for _, c := range list {
+		if c.Pid > 0 {
+			out[c.Pid]++
+		}
+	}
+	return out
+}
+
+// processUsername возвращает имя владельца процесса; cache — uid → имя в пределах одного запроса.
+func processUsername(p *process.Process, cache map[int32]string) string {
+	uids, err := p.Uids()
+	if err != nil || len(uids) == 0 {
+		// На Windows uid нет — берём имя напрямую.
+		name, _ := p.Username()
+		return name
+	}
+	uid := uids[0]
+	if name, ok := cache[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(int(uid))
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	cache[uid] = name
+	return name
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
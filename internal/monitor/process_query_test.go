@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func cpuRows(cpu map[int32]float64) []processRow {
+	rows := make([]processRow, 0, len(cpu))
+	for pid, v := range cpu {
+		rows = append(rows, newProcessRow(ProcessInfo{PID: pid, CPUPercent: v}, SortCPU))
+	}
+	return rows
+}
+
+// pages проходит все страницы по курсору; rows(page) — таблица процессов на момент запроса страницы.
+func pages(t *testing.T, q ProcessQuery, rows func(page int) []processRow) [][]int32 {
+	t.Helper()
+	var out [][]int32
+	for page := 0; page < 10; page++ {
+		f, err := newProcessFilter(q, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := rows(page)
+		start, end, next := f.window(r)
+		var pids []int32
+		for _, row := range r[start:end] {
+			pids = append(pids, row.info.PID)
+		}
+		out = append(out, pids)
+		if next == "" {
+			return out
+		}
+		q.Cursor = next
+	}
+	t.Fatal("cursor does not end")
+	return nil
+}
+
+func TestProcessWindowCursor(t *testing.T) {
+	cpu := map[int32]float64{1: 0, 2: 50, 3: 50, 4: 10, 5: 90, 6: 50, 7: 0}
+	got := pages(t, ProcessQuery{Sort: SortCPU, Limit: 3}, func(int) []processRow { return cpuRows(cpu) })
+	// По убыванию CPU, при равенстве — по PID.
+	want := [][]int32{{5, 2, 3}, {6, 4, 1}, {7}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("pages %v, want %v", got, want)
+	}
+
+	// Между страницами процессы исчезают и появляются: курсор продолжает с той же позиции,
+	// без повторов и пропусков оставшихся.
+	got = pages(t, ProcessQuery{Sort: SortCPU, Limit: 3}, func(page int) []processRow {
+		if page == 0 {
+			return cpuRows(cpu)
+		}
+		next := map[int32]float64{1: 0, 4: 10, 6: 50, 7: 0, 8: 99, 9: 20}
+		return cpuRows(next)
+	})
+	want = [][]int32{{5, 2, 3}, {6, 9, 4}, {1, 7}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("pages after change %v, want %v", got, want)
+	}
+}
+
+func TestProcessWindowOffset(t *testing.T) {
+	rows := make([]processRow, 0, 5)
+	for _, name := range []string{"zsh", "Bash", "cron", "atd", "bash"} {
+		rows = append(rows, newProcessRow(ProcessInfo{PID: int32(len(rows) + 1), Name: name}, SortName))
+	}
+	f, err := newProcessFilter(ProcessQuery{Sort: SortName, Offset: 1, Limit: 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, end, next := f.window(rows)
+	var names []string
+	for _, r := range rows[start:end] {
+		names = append(names, r.info.Name)
+	}
+	// Имя без учёта регистра, по возрастанию: atd, Bash(2), bash(5), cron, zsh.
+	if !slices.Equal(names, []string{"Bash", "bash"}) || start != 1 || next == "" {
+		t.Errorf("window = %v (start %d, next %q)", names, start, next)
+	}
+}
+
+func TestProcessQueryNormalize(t *testing.T) {
+	tests := []struct {
+		q         ProcessQuery
+		sort      string
+		order     string
+		limit     int
+		wantError bool
+	}{
+		{ProcessQuery{}, SortPID, "asc", defaultPageLimit, false},
+		{ProcessQuery{Sort: "CPU"}, SortCPU, "desc", defaultPageLimit, false},
+		{ProcessQuery{Sort: "memory", Order: "ASC", Limit: 5000}, SortRSS, "asc", maxPageLimit, false},
+		{ProcessQuery{Sort: SortName, Limit: 10}, SortName, "asc", 10, false},
+		{ProcessQuery{Sort: "bogus"}, "", "", 0, true},
+		{ProcessQuery{Sort: SortCount}, "", "", 0, true},
+		{ProcessQuery{Order: "up"}, "", "", 0, true},
+	}
+	for _, tt := range tests {
+		q := tt.q
+		err := q.normalize()
+		if tt.wantError {
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("normalize(%+v) error = %v, want ErrInvalidQuery", tt.q, err)
+			}
+			continue
+		}
+		if err != nil || q.Sort != tt.sort || q.Order != tt.order || q.Limit != tt.limit {
+			t.Errorf("normalize(%+v) = %s %s %d, %v", tt.q, q.Sort, q.Order, q.Limit, err)
+		}
+	}
+}
+
+func TestDecodeProcessCursor(t *testing.T) {
+	c := encodeProcessCursor(processCursor{Sort: SortCPU, Desc: true, Num: 12.5, PID: 42})
+	got, err := decodeProcessCursor(c, SortCPU, true)
+	if err != nil || got.Num != 12.5 || got.PID != 42 {
+		t.Fatalf("decode = %+v, %v", got, err)
+	}
+	for _, tt := range []struct {
+		cursor, sort string
+		desc         bool
+	}{
+		{c, SortRSS, true},  // другой ключ сортировки
+		{c, SortCPU, false}, // другой порядок
+		{"not base64!", SortCPU, true},
+		{"bm90IGpzb24", SortCPU, true}, // "not json"
+	} {
+		if _, err := decodeProcessCursor(tt.cursor, tt.sort, tt.desc); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("decode(%q, %s, %v) error = %v, want ErrInvalidQuery", tt.cursor, tt.sort, tt.desc, err)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package monitor
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// createTimeTolerance — насколько могут расходиться два чтения CreateTime одного процесса, мс.
+// На Linux это целые секунды от btime, а тот сдвигается на секунду, когда подводят системные
+// часы; без допуска такой сдвиг выглядел бы как завершение и перезапуск всех процессов.
+const createTimeTolerance = 1000
+
+// processTable — таблица процессов коллектора. Обновляется раз в тик и помнит
+// процессорное время и счётчики I/O каждого процесса с прошлого тика, чтобы CPU% и
+// скорости диска считались за интервал, а не в среднем с момента старта.
+// Используется только из loop().
+type processTable struct {
+	entries  map[int32]*processEntry
+	users    map[int32]string // uid → имя
+	lastScan time.Time
+	skewMs   int64 // см. createTimeSkewMs
+}
+
+type processEntry struct {
+	createTime int64   // мс; отличает процесс от нового с тем же PID
+	cpuTime    float64 // user+system, секунды
+	io         *process.IOCountersStat
+	sampledAt  time.Time
+	username   string
+	// Для событий запуска/завершения.
+	name    string
+	ppid    int32
+	cmdline string
+	peakCPU float64
+	peakRSS uint64
+	// Состояние процесса и когда оно замечено (для отчёта о зависших процессах).
+	state      string
+	stateSince time.Time
+	stateFirst bool // состояние застали при первом обходе — реально оно длится дольше
+}
+
+// processSnapshot — процесс в снимке таблицы. Снимок после публикации не меняется.
+type processSnapshot struct {
+	proc       *process.Process
+	ppid       int32
+	rss        uint64 // байты; в info — округлённый вниз до МБ
+	info       ProcessInfo
+	stateSince time.Time
+	stateFirst bool
+}
+
+func newProcessTable() *processTable {
+	return &processTable{
+		entries: make(map[int32]*processEntry),
+		users:   make(map[int32]string),
+		skewMs:  createTimeSkewMs(),
+	}
+}
+
+// refresh перечитывает процессы и возвращает новый снимок, отсортированный по PID,
+// и события запуска/завершения с прошлого обхода (при первом обходе событий нет).
+func (t *processTable) refresh() ([]processSnapshot, []ProcessEvent, error) {
+	pids, err := process.Pids()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	seen := make(map[int32]bool, len(pids))
+	out := make([]processSnapshot, 0, len(pids))
+	var events, started []ProcessEvent
+	for _, pid := range pids {
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+		ct, _ := p.CreateTime()
+		if ct > 0 {
+			ct += t.skewMs
+		}
+		e, ok := t.entries[pid]
+		if ok && !sameCreateTime(e.createTime, ct) {
+			// PID переиспользован — это другой процесс.
+			events = append(events, e.exitEvent(pid, now))
+			ok = false
+		}
+		if !ok {
+			e = &processEntry{createTime: ct, username: processUsername(p, t.users)}
+			t.entries[pid] = e
+			// Командную строку читаем и при первом обходе: после завершения её уже не прочитать,
+			// а событие завершения должно её содержать.
+			e.cmdline, _ = p.Cmdline()
+			if !t.lastScan.IsZero() {
+				started = append(started, ProcessEvent{Type: ProcessEventStart, PID: pid})
+			}
+		}
+		seen[pid] = true
+
+		s := processSnapshot{proc: p, info: ProcessInfo{PID: pid, Name: processName(p), Username: e.username}}
+		s.ppid, _ = p.Ppid()
+		if ct > 0 {
+			s.info.StartTime = ct / 1000
+		}
+		if status, err := p.Status(); err == nil && len(status) > 0 {
+			s.info.Status = status[0]
+		}
+		if s.info.Status != e.state {
+			e.state, e.stateSince, e.stateFirst = s.info.Status, now, t.lastScan.IsZero()
+		}
+		s.stateSince, s.stateFirst = e.stateSince, e.stateFirst
+		if mem, err := p.MemoryInfo(); err == nil && mem != nil {
+			s.rss, s.info.RSSMB = mem.RSS, mem.RSS/(1024*1024)
+		}
+		sec, fromStart := t.window(e, ok, now)
+		if times, err := p.Times(); err == nil && times != nil {
+			cpu := times.User + times.System
+			// 100% = одно ядро, как в top.
+			s.info.CPUPercent = intervalRate(cpu, e.cpuTime, sec, fromStart) * 100
+			e.cpuTime = cpu
+		}
+		prevIO := e.io
+		e.io = nil
+		if io, err := p.IOCounters(); err == nil && io != nil {
+			s.info.ReadSyscalls, s.info.WriteSyscalls = io.ReadCount, io.WriteCount
+			if prevIO != nil || fromStart {
+				if prevIO == nil {
+					prevIO = &process.IOCountersStat{}
+				}
+				s.info.DiskReadBps = intervalRate(float64(io.ReadBytes), float64(prevIO.ReadBytes), sec, fromStart)
+				s.info.DiskWriteBps = intervalRate(float64(io.WriteBytes), float64(prevIO.WriteBytes), sec, fromStart)
+				s.info.ReadSyscallsPS = intervalRate(float64(io.ReadCount), float64(prevIO.ReadCount), sec, fromStart)
+				s.info.WriteSyscallsPS = intervalRate(float64(io.WriteCount), float64(prevIO.WriteCount), sec, fromStart)
+			}
+			e.io = io
+		} else if errors.Is(err, fs.ErrPermission) {
+			// /proc/<pid>/io чужого процесса без CAP_SYS_PTRACE не читается — это не ошибка.
+			s.info.IODenied = true
+		}
+		e.sampledAt = now
+		e.name, e.ppid = s.info.Name, s.ppid
+		e.peakCPU = max(e.peakCPU, s.info.CPUPercent)
+		e.peakRSS = max(e.peakRSS, s.info.RSSMB)
+		out = append(out, s)
+	}
+	for pid, e := range t.entries {
+		if !seen[pid] {
+			events = append(events, e.exitEvent(pid, now))
+			delete(t.entries, pid)
+		}
+	}
+	// Имя родителя заполняем после обхода: родитель мог быть прочитан позже потомка.
+	for _, ev := range started {
+		e := t.entries[ev.PID]
+		ev = e.event(ProcessEventStart, ev.PID, now)
+		if parent, ok := t.entries[ev.PPID]; ok {
+			ev.ParentName = parent.name
+		}
+		events = append(events, ev)
+	}
+	t.lastScan = now
+	return out, events, nil
+}
+
+// sameCreateTime сообщает, что a и b — время старта одного процесса (см. createTimeTolerance).
+func sameCreateTime(a, b int64) bool {
+	return a-b <= createTimeTolerance && b-a <= createTimeTolerance
+}
+
+func (e *processEntry) event(typ string, pid int32, now time.Time) ProcessEvent {
+	ev := ProcessEvent{
+		Type:     typ,
+		Time:     now.UnixMilli(),
+		PID:      pid,
+		PPID:     e.ppid,
+		Name:     e.name,
+		Cmdline:  e.cmdline,
+		Username: e.username,
+	}
+	if e.createTime > 0 {
+		ev.StartTime = e.createTime / 1000
+	}
+	return ev
+}
+
+// exitEvent — процесс пропал; время жизни считаем до момента, когда это замечено.
+func (e *processEntry) exitEvent(pid int32, now time.Time) ProcessEvent {
+	ev := e.event(ProcessEventExit, pid, now)
+	if e.createTime > 0 {
+		ev.LifetimeSec = now.Sub(time.UnixMilli(e.createTime)).Seconds()
+	}
+	ev.PeakCPUPercent, ev.PeakRSSMB = e.peakCPU, e.peakRSS
+	return ev
+}
+
+// window — за какой интервал считать скорости: с прошлого замера процесса или,
+// если процесс появился после прошлого тика, — с момента его старта (fromStart).
+// 0 — интервала нет (первый обход таблицы).
+func (t *processTable) window(e *processEntry, known bool, now time.Time) (sec float64, fromStart bool) {
+	switch {
+	case known && !e.sampledAt.IsZero():
+		return now.Sub(e.sampledAt).Seconds(), false
+	case !t.lastScan.IsZero() && e.createTime >= t.lastScan.UnixMilli():
+		return now.Sub(time.UnixMilli(e.createTime)).Seconds(), true
+	}
+	return 0, false
+}
+
+// intervalRate — скорость роста счётчика за sec секунд; при fromStart счётчик растёт от нуля.
+func intervalRate(cur, prev, sec float64, fromStart bool) float64 {
+	if sec <= 0 {
+		return 0
+	}
+	if fromStart {
+		prev = 0
+	}
+	return max((cur-prev)/sec, 0)
+}
+
+// ListTopProcessesByIO возвращает топ limit процессов по дисковому I/O (чтение+запись) за последний интервал.
+func (c *Collector) ListTopProcessesByIO(limit int) []ProcessInfo {
+	if limit <= 0 {
+		limit = 5
+	}
+	var list []ProcessInfo
+	for _, s := range c.processSnapshots() {
+		if s.info.DiskReadBps+s.info.DiskWriteBps > 0 {
+			list = append(list, s.info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].DiskReadBps+list[i].DiskWriteBps > list[j].DiskReadBps+list[j].DiskWriteBps
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// processSnapshots возвращает последний снимок таблицы процессов.
+func (c *Collector) processSnapshots() []processSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.procList
+}
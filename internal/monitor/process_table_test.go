@@ -0,0 +1,21 @@
+package monitor
+
+import "testing"
+
+func TestSameCreateTime(t *testing.T) {
+	tests := []struct {
+		a, b int64
+		want bool
+	}{
+		{1_700_000_000_000, 1_700_000_000_000, true},
+		{1_700_000_000_000, 1_700_000_001_000, true}, // btime сдвинулся на секунду
+		{1_700_000_001_000, 1_700_000_000_000, true},
+		{1_700_000_000_000, 1_700_000_002_000, false},
+		{1_700_000_000_000, 1_700_000_000_000 - 1001, false},
+	}
+	for _, tt := range tests {
+		if got := sameCreateTime(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameCreateTime(%d, %d) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package monitor
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessNode — процесс в дереве с суммарными CPU/памятью по всему поддереву.
+type ProcessNode struct {
+	ProcessInfo
+	PPID           int32          `json:"ppid"`
+	TreeCPUPercent float64        `json:"tree_cpu_percent"`
+	TreeRSSMB      uint64         `json:"tree_rss_mb"`
+	TreeCount      int            `json:"tree_count"` // процессов в поддереве, включая сам узел
+	Children       []*ProcessNode `json:"children,omitempty"`
+}
+
+// KillTreeResult — итог завершения дерева процессов.
+type KillTreeResult struct {
+	Killed []int32          `json:"killed"`
+	Failed map[int32]string `json:"failed,omitempty"`
+}
+
+// BuildProcessTree строит дерево процессов из таблицы последнего тика.
+// rootPID > 0 — вернуть только поддерево этого процесса.
+func (c *Collector) BuildProcessTree(rootPID int32) ([]*ProcessNode, error) {
+	snaps := c.processSnapshots()
+	nodes := make(map[int32]*ProcessNode, len(snaps))
+	for _, s := range snaps {
+		nodes[s.info.PID] = &ProcessNode{ProcessInfo: s.info, PPID: s.ppid}
+	}
+	roots := linkProcessNodes(nodes)
+	if rootPID > 0 {
+		n, ok := nodes[rootPID]
+		if !ok {
+			return nil, ErrProcessNotFound
+		}
+		for _, pid := range descendantsPostOrder(n) {
+			fillPriority(&nodes[pid].ProcessInfo)
+		}
+		return []*ProcessNode{n}, nil
+	}
+	for _, n := range nodes {
+		fillPriority(&n.ProcessInfo)
+	}
+	return roots, nil
+}
+
+// snapshotProcessNodes читает связи родитель-потомок заново, без кэша:
+// для операций над деревом важны процессы, появившиеся после последнего тика.
+func snapshotProcessNodes() (map[int32]*ProcessNode, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[int32]*ProcessNode, len(procs))
+	for _, p := range procs {
+		n := &ProcessNode{ProcessInfo: ProcessInfo{PID: p.Pid, Name: processName(p)}}
+		n.PPID, _ = p.Ppid()
+		nodes[p.Pid] = n
+	}
+	return nodes, nil
+}
+
+// linkProcessNodes связывает узлы по PPID, считает суммы по поддеревьям и возвращает корни.
+// Корень — процесс без родителя в снимке (PID 1, kthreadd, осиротевшие на Windows).
+func linkProcessNodes(nodes map[int32]*ProcessNode) []*ProcessNode {
+	var roots []*ProcessNode
+	for _, n := range nodes {
+		parent, ok := nodes[n.PPID]
+		if !ok || n.PPID == n.PID {
+			roots = append(roots, n)
+			continue
+		}
+		parent.Children = append(parent.Children, n)
+	}
+	visited := make(map[int32]bool, len(nodes))
+	for _, r := range roots {
+		sumProcessTree(r, visited)
+	}
+	sortProcessNodes(roots)
+	return roots
+}
+
+func sumProcessTree(n *ProcessNode, visited map[int32]bool) {
+	visited[n.PID] = true
+	n.TreeCPUPercent = n.CPUPercent
+	n.TreeRSSMB = n.RSSMB
+	n.TreeCount = 1
+	for _, c := range n.Children {
+		// Защита от циклов при переиспользовании PID между чтениями.
+		if visited[c.PID] {
+			continue
+		}
+		sumProcessTree(c, visited)
+		n.TreeCPUPercent += c.TreeCPUPercent
+		n.TreeRSSMB += c.TreeRSSMB
+		n.TreeCount += c.TreeCount
+	}
+}
+
+func sortProcessNodes(list []*ProcessNode) {
+	sort.Slice(list, func(i, j int) bool { return list[i].PID < list[j].PID })
+	for _, n := range list {
+		sortProcessNodes(n.Children)
+	}
+}
+
+// descendantsPostOrder возвращает PID поддерева root: сначала самые глубокие потомки, root — последним.
+func descendantsPostOrder(root *ProcessNode) []int32 {
+	var out []int32
+	visited := make(map[int32]bool)
+	var walk func(n *ProcessNode)
+	walk = func(n *ProcessNode) {
+		if visited[n.PID] {
+			return
+		}
+		visited[n.PID] = true
+		for _, c := range n.Children {
+			walk(c)
+		}
+		out = append(out, n.PID)
+	}
+	walk(root)
+	return out
+}
+
+// ProcessTreePIDs возвращает pid и всех его потомков в порядке завершения: дети раньше родителей.
+func ProcessTreePIDs(pid int32) ([]int32, error) {
+	nodes, err := snapshotProcessNodes()
+	if err != nil {
+		return nil, err
+	}
+	linkProcessNodes(nodes)
+	root, ok := nodes[pid]
+	if !ok {
+		return nil, ErrProcessNotFound
+	}
+	return descendantsPostOrder(root), nil
+}
+
+// KillProcessTree завершает процесс pid и всех его потомков: сначала детей, затем родителя.
+func KillProcessTree(pid int32) (KillTreeResult, error) {
+	pids, err := ProcessTreePIDs(pid)
+	if err != nil {
+		return KillTreeResult{}, err
+	}
+	return KillProcessList(pid, pids)
+}
+
+// KillProcessList завершает процессы pids по порядку (результат ProcessTreePIDs для root).
+// Ошибка возвращается, только если не удалось завершить root.
+func KillProcessList(root int32, pids []int32) (KillTreeResult, error) {
+	res := KillTreeResult{Killed: []int32{}}
+	for _, p := range pids {
+		if err := KillProcess(p); err != nil {
+			if res.Failed == nil {
+				res.Failed = make(map[int32]string)
+			}
+			res.Failed[p] = err.Error()
+			continue
+		}
+		res.Killed = append(res.Killed, p)
+	}
+	if res.Failed[root] != "" {
+		return res, errors.New("failed to kill root process: " + res.Failed[root])
+	}
+	return res, nil
+}
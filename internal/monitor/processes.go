@@ -3,10 +3,7 @@ package monitor
 import (
 	"fmt"
 	"path/filepath"
-	"sort"
-	"strings"
 
-	"github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
@@ -15,111 +12,45 @@ type ProcessInfo struct {
 	PID              int32   `json:"pid"`
 	Name             string  `json:"name"`
 	RSSMB            uint64  `json:"rss_mb,omitempty"`
+	// PSS, USS, общая память и swap (Linux) — только при сортировке по ним и на странице с метриками.
+	PSSMB            uint64  `json:"pss_mb,omitempty"`
+	USSMB            uint64  `json:"uss_mb,omitempty"`
+	SharedMB         uint64  `json:"shared_mb,omitempty"`
+	SwapMB           uint64  `json:"swap_mb,omitempty"`
 	Status           string  `json:"status,omitempty"`
+	Username         string  `json:"username,omitempty"`
+	StartTime        int64   `json:"start_time,omitempty"` // unix, секунды
 	CPUPercent       float64 `json:"cpu_percent,omitempty"`
+	DiskReadBps      float64 `json:"disk_read_bps,omitempty"`  // байт/с с диска за интервал
+	DiskWriteBps     float64 `json:"disk_write_bps,omitempty"` // байт/с на диск за интервал
+	ReadSyscalls     uint64  `json:"read_syscalls,omitempty"`  // всего read-вызовов (syscr)
+	WriteSyscalls    uint64  `json:"write_syscalls,omitempty"`
+	ReadSyscallsPS   float64 `json:"read_syscalls_per_sec,omitempty"`
+	WriteSyscallsPS  float64 `json:"write_syscalls_per_sec,omitempty"`
+	IODenied         bool    `json:"io_denied,omitempty"` // нет прав на счётчики I/O процесса
 	NetBytesSent     uint64  `json:"net_bytes_sent,omitempty"`
 	NetBytesRecv     uint64  `json:"net_bytes_recv,omitempty"`
 	ConnectionsCount int     `json:"connections_count,omitempty"`
+	GPUPercent       float64 `json:"gpu_percent,omitempty"`   // самый загруженный движок GPU (DRM fdinfo)
+	GPUMemoryMB      uint64  `json:"gpu_memory_mb,omitempty"` // сумма по всем GPU
+	GPUEngines       map[string]float64 `json:"gpu_engines,omitempty"`
+	Nice             *int32  `json:"nice,omitempty"`
+	Affinity         string  `json:"affinity,omitempty"`    // список CPU, например "0-3"
+	IOPriority       string  `json:"io_priority,omitempty"` // "best-effort/4", только Linux
 }
 
-// ListProcesses возвращает список процессов. limit — макс. количество, query — фильтр по имени (подстрока).
-// withMetrics при true добавляет CPU%, сеть и соединения (медленнее, limit ограничивается 100).
-func ListProcesses(limit int, query string, withMetrics bool) ([]ProcessInfo, error) {
-	if limit <= 0 {
-		limit = 200
-	}
-	if limit > 500 {
-		limit = 500
-	}
-	if withMetrics && limit > 100 {
-		limit = 100
-	}
-	procs, err := process.Processes()
-	if err != nil {
-		return nil, err
-	}
-	query = strings.TrimSpace(strings.ToLower(query))
-	var out []ProcessInfo
-	for _, p := range procs {
-		if len(out) >= limit {
-			break
-		}
-		name, _ := p.Name()
-		if name == "" {
-			if exe, err := p.Exe(); err == nil && exe != "" {
-				name = filepath.Base(exe)
-			}
-			if name == "" {
-				name = fmt.Sprintf("PID %d", p.Pid)
-			}
-		}
-		if query != "" && !strings.Contains(strings.ToLower(name), query) {
-			continue
-		}
-		info := ProcessInfo{PID: p.Pid, Name: name}
-		if mem, err := p.MemoryInfo(); err == nil && mem != nil {
-			info.RSSMB = mem.RSS / (1024 * 1024)
-		}
-		if status, err := p.Status(); err == nil && len(status) > 0 {
-			info.Status = status[0]
-		}
-		if withMetrics {
-			if pct, err := p.CPUPercent(); err == nil {
-				info.CPUPercent = pct
-			}
-			// NetIOCounters per process есть в gopsutil v4; в v3 на Windows нет — оставляем 0.
-			if conns, err := net.ConnectionsPid("all", p.Pid); err == nil {
-				info.ConnectionsCount = len(conns)
-			}
+// processName возвращает имя процесса; если его нет — имя исполняемого файла или "PID N".
+func processName(p *process.Process) string {
+	name, _ := p.Name()
+	if name == "" {
+		if exe, err := p.Exe(); err == nil && exe != "" {
+			name = filepath.Base(exe)
 		}
-		out = append(out, info)
-	}
-	return out, nil
-}
-
-// ListTopProcessesByCPU возвращает топ limit процессов по загрузке CPU (для виджета Hub).
-func ListTopProcessesByCPU(limit int) ([]ProcessInfo, error) {
-	if limit <= 0 {
-		limit = 5
-	}
-	if limit > 20 {
-		limit = 20
-	}
-	procs, err := process.Processes()
-	if err != nil {
-		return nil, err
-	}
-	type scored struct {
-		info  ProcessInfo
-		score float64
-	}
-	var scoredList []scored
-	for _, p := range procs {
-		name, _ := p.Name()
 		if name == "" {
-			if exe, err := p.Exe(); err == nil && exe != "" {
-				name = filepath.Base(exe)
-			}
-			if name == "" {
-				name = fmt.Sprintf("PID %d", p.Pid)
-			}
-		}
-		pct, err := p.CPUPercent()
-		if err != nil || pct <= 0 {
-			continue
+			name = fmt.Sprintf("PID %d", p.Pid)
 		}
-		info := ProcessInfo{PID: p.Pid, Name: name, CPUPercent: pct}
-		if mem, err := p.MemoryInfo(); err == nil && mem != nil {
-			info.RSSMB = mem.RSS / (1024 * 1024)
-		}
-		scoredList = append(scoredList, scored{info: info, score: pct})
-	}
-	sort.Slice(scoredList, func(i, j int) bool { return scoredList[i].score > scoredList[j].score })
-	out := make([]ProcessInfo, 0, limit)
-	for i := 0; i < limit && i < len(scoredList); i++ {
-		out = append(out, scoredList[i].info)
 	}
-	return out, nil
+	return name
 }
 
 // KillProcess завершает процесс по PID. Возвращает ошибку при отказе или отсутствии процесса.
@@ -0,0 +1,139 @@
+package monitor
+
+import (
+	"sort"
+	"strings"
+)
+
+// processGPU — использование GPU одним процессом (сумма по всем GPU и DRM-клиентам).
+type processGPU struct {
+	Percent  float64
+	MemoryMB uint64
+	Engines  map[string]float64
+}
+
+// mergeProcessGPU объединяет видеопамять из nvidia-smi и загрузку/память из DRM fdinfo по PID.
+// Клиенты драйвера nvidia в fdinfo пропускаем — их память уже пришла из nvidia-smi.
+func mergeProcessGPU(nvidia []GPUProcess, drm []drmUsage) map[int32]processGPU {
+	out := make(map[int32]processGPU)
+	for _, p := range nvidia {
+		u := out[p.PID]
+		u.MemoryMB += p.UsedMemoryMB
+		out[p.PID] = u
+	}
+	for _, d := range drm {
+		if strings.HasPrefix(d.Driver, "nvidia") {
+			continue
+		}
+		u := out[d.PID]
+		u.MemoryMB += d.MemoryBytes / (1024 * 1024)
+		for engine, pct := range d.Engines {
+			if u.Engines == nil {
+				u.Engines = make(map[string]float64)
+			}
+			u.Engines[engine] = clampPercent(u.Engines[engine] + pct)
+			if u.Engines[engine] > u.Percent {
+				u.Percent = u.Engines[engine]
+			}
+		}
+		out[d.PID] = u
+	}
+	for pid, u := range out {
+		if u.MemoryMB == 0 && u.Percent == 0 {
+			delete(out, pid)
+		}
+	}
+	return out
+}
+
+// ListProcesses возвращает страницу процессов по запросу q из таблицы процессов последнего тика,
+// вместе с использованием GPU (по нему можно сортировать: sort=gpu|gpu_memory).
+func (c *Collector) ListProcesses(q ProcessQuery) (ProcessPage, error) {
+	c.mu.RLock()
+	snaps, gpu := c.procList, c.procGPU
+	c.mu.RUnlock()
+	return queryProcesses(q, snaps, gpu, c.mem)
+}
+
+// ListTopProcessesByCPU возвращает топ limit процессов по загрузке CPU за последний интервал (для виджета Hub).
+func (c *Collector) ListTopProcessesByCPU(limit int) []ProcessInfo {
+	if limit <= 0 {
+		limit = 5
+	}
+	if limit > 20 {
+		limit = 20
+	}
+	var list []ProcessInfo
+	for _, s := range c.processSnapshots() {
+		if s.info.CPUPercent > 0 {
+			list = append(list, s.info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CPUPercent > list[j].CPUPercent })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	c.attachGPUUsage(list)
+	return list
+}
+
+// ListTopProcessesByGPU возвращает топ limit процессов, использующих GPU.
+// by: "gpu" — по загрузке (затем по памяти), "gpu_memory" — по видеопамяти. query — фильтр по имени.
+func (c *Collector) ListTopProcessesByGPU(limit int, by, query string) []ProcessInfo {
+	if limit <= 0 {
+		limit = 5
+	}
+	c.mu.RLock()
+	snaps, usage := c.procList, c.procGPU
+	c.mu.RUnlock()
+	if len(usage) == 0 {
+		return nil
+	}
+
+	query = strings.TrimSpace(strings.ToLower(query))
+	list := make([]ProcessInfo, 0, len(usage))
+	for _, s := range snaps {
+		u, ok := usage[s.info.PID]
+		if !ok {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(s.info.Name), query) {
+			continue
+		}
+		info := s.info
+		info.GPUPercent, info.GPUMemoryMB, info.GPUEngines = u.Percent, u.MemoryMB, u.Engines
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if by == "gpu_memory" {
+			if a.GPUMemoryMB != b.GPUMemoryMB {
+				return a.GPUMemoryMB > b.GPUMemoryMB
+			}
+			return a.GPUPercent > b.GPUPercent
+		}
+		if a.GPUPercent != b.GPUPercent {
+			return a.GPUPercent > b.GPUPercent
+		}
+		return a.GPUMemoryMB > b.GPUMemoryMB
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+func (c *Collector) attachGPUUsage(list []ProcessInfo) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.procGPU) == 0 {
+		return
+	}
+	for i := range list {
+		if u, ok := c.procGPU[list[i].PID]; ok {
+			list[i].GPUPercent = u.Percent
+			list[i].GPUMemoryMB = u.MemoryMB
+			list[i].GPUEngines = u.Engines
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package monitor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Решения защиты (ProtectionCheck.Decision).
+const (
+	ProtectionAllow   = "allow"
+	ProtectionConfirm = "confirm" // выполнить можно только с токеном подтверждения
+	ProtectionDeny    = "deny"    // не выполняется никогда
+)
+
+// Сколько живёт токен подтверждения.
+const confirmTokenTTL = time.Minute
+
+// Системные процессы, без которых рушится ОС или сессия пользователя (сравнение без учёта регистра).
+var criticalProcessNames = []string{
+	// Linux
+	"systemd", "init", "systemd-logind", "systemd-journald", "dbus-daemon", "dbus-broker",
+	"gnome-session-binary", "gnome-shell", "ksmserver", "plasmashell", "kwin_x11", "kwin_wayland",
+	"xfce4-session", "lxsession", "mate-session", "cinnamon-session",
+	"gdm", "gdm3", "lightdm", "sddm", "Xorg", "Xwayland",
+	// macOS
+	"launchd", "kernel_task", "WindowServer", "loginwindow",
+	// Windows
+	"System", "Registry", "smss.exe", "csrss.exe", "wininit.exe", "winlogon.exe",
+	"services.exe", "lsass.exe", "dwm.exe",
+}
+
+// ProtectionCheck — решение защиты для одного процесса.
+type ProtectionCheck struct {
+	PID      int32  `json:"pid"`
+	Name     string `json:"name,omitempty"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Authorization — решение по действию над набором процессов.
+type Authorization struct {
+	Action  string            `json:"action"`
+	Allowed bool              `json:"allowed"`
+	Checks  []ProtectionCheck `json:"checks"`
+	// ConfirmToken выдаётся, если действие требует подтверждения: повторите запрос с ним.
+	ConfirmToken       string `json:"confirm_token,omitempty"`
+	ConfirmExpiresInMs int64  `json:"confirm_expires_in_ms,omitempty"`
+}
+
+// Denied сообщает, что хотя бы один процесс защищён без возможности подтверждения.
+func (a Authorization) Denied() bool {
+	return slices.ContainsFunc(a.Checks, func(c ProtectionCheck) bool { return c.Decision == ProtectionDeny })
+}
+
+// NeedsConfirmation сообщает, что действие не разрешено только из-за отсутствия подтверждения.
+func (a Authorization) NeedsConfirmation() bool {
+	return !a.Allowed && !a.Denied()
+}
+
+// GuardConfig — настройки ProcessGuard.
+type GuardConfig struct {
+	// HubPID — PID Hub, запустившего Eye; 0 — Eye работает сам по себе.
+	HubPID int32
+	// ProtectedNames возвращает текущий список имён из настроек; такие процессы — только с подтверждением.
+	ProtectedNames func() []string
+}
+
+// ProcessGuard проверяет разрушительные действия над процессами (kill, terminate, signal, suspend, priority).
+// Запрещены всегда: init, потоки ядра, сам Eye, его Hub и системные процессы сессии.
+// Требуют подтверждения: процессы из protected_names и процессы системных пользователей.
+type ProcessGuard struct {
+	selfPID int32
+	hubPID  int32
+	names   func() []string
+
+	mu     sync.Mutex
+	tokens map[string]confirmToken
+}
+
+type confirmToken struct {
+	action  string
+	targets []processIdentity
+	subject string // для AuthorizeSubject; у токенов на процессы пусто
+	expires time.Time
+}
+
+// NewProcessGuard создаёт защиту с настройками cfg.
+func NewProcessGuard(cfg GuardConfig) *ProcessGuard {
+	names := cfg.ProtectedNames
+	if names == nil {
+		names = func() []string { return nil }
+	}
+	return &ProcessGuard{
+		selfPID: int32(os.Getpid()),
+		hubPID:  cfg.HubPID,
+		names:   names,
+		tokens:  make(map[string]confirmToken),
+	}
+}
+
+// Check возвращает решение защиты для процесса pid.
+func (g *ProcessGuard) Check(pid int32) ProtectionCheck {
+	c := ProtectionCheck{PID: pid, Decision: ProtectionAllow}
+	deny := func(reason string) ProtectionCheck {
+		c.Decision, c.Reason = ProtectionDeny, reason
+		return c
+	}
+	p, err := process.NewProcess(pid)
+	if err == nil {
+		c.Name = processName(p)
+	}
+	switch {
+	case pid == g.selfPID:
+		return deny("nekkus eye itself")
+	case g.hubPID > 0 && pid == g.hubPID:
+		return deny("parent nekkus hub")
+	case pid == 1:
+		return deny("init process")
+	case err != nil:
+		// Несуществующий процесс не защищаем: действие само вернёт not_found.
+		return c
+	}
+	switch {
+	case isKernelThread(p):
+		return deny("kernel thread")
+	case containsFold(criticalProcessNames, c.Name):
+		return deny("system critical process")
+	case containsFold(g.names(), c.Name):
+		c.Decision, c.Reason = ProtectionConfirm, "protected name"
+	case systemOwned(p):
+		c.Decision, c.Reason = ProtectionConfirm, "owned by a system account"
+	}
+	return c
+}
+
+// Authorize проверяет действие action над процессами pids. Если нужно подтверждение и token
+// не подходит — выдаёт новый токен. Подходящий токен одноразовый: он привязан к действию и
+// тем же процессам (с учётом времени старта) и расходуется, если dryRun не задан.
+func (g *ProcessGuard) Authorize(action string, pids []int32, token string, dryRun bool) Authorization {
+	a := Authorization{Action: action, Checks: make([]ProtectionCheck, 0, len(pids))}
+	confirm := false
+	for _, pid := range pids {
+		c := g.Check(pid)
+		confirm = confirm || c.Decision == ProtectionConfirm
+		a.Checks = append(a.Checks, c)
+	}
+	if a.Denied() {
+		return a
+	}
+	if !confirm {
+		a.Allowed = true
+		return a
+	}
+
+	targets := make([]processIdentity, 0, len(pids))
+	for _, pid := range pids {
+		id, _ := identify(pid)
+		id.pid = pid
+		targets = append(targets, id)
+	}
+	g.confirm(&a, confirmToken{action: action, targets: targets}, token, !dryRun)
+	return a
+}
+
+// AuthorizeSubject требует подтверждения действия action над subject — чем-то, что само
+// потом будет действовать над защищёнными процессами (например, правило с confirmed).
+// Токен одноразовый и подходит только к тому же action и subject.
+func (g *ProcessGuard) AuthorizeSubject(action, subject, token string) Authorization {
+	a := Authorization{Action: action, Checks: []ProtectionCheck{}}
+	g.confirm(&a, confirmToken{action: action, subject: subject}, token, true)
+	return a
+}
+
+// confirm разрешает a, если token выдан для того же, что want (и расходует его при consume),
+// иначе выдаёт в a новый токен.
+func (g *ProcessGuard) confirm(a *Authorization, want confirmToken, token string, consume bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	for t, ct := range g.tokens {
+		if now.After(ct.expires) {
+			delete(g.tokens, t)
+		}
+	}
+	if ct, ok := g.tokens[token]; ok && ct.action == want.action && ct.subject == want.subject &&
+		slices.Equal(ct.targets, want.targets) {
+		a.Allowed = true
+		if consume {
+			delete(g.tokens, token)
+		}
+		return
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	a.ConfirmToken = hex.EncodeToString(b)
+	a.ConfirmExpiresInMs = confirmTokenTTL.Milliseconds()
+	want.expires = now.Add(confirmTokenTTL)
+	g.tokens[a.ConfirmToken] = want
+}
+
+// systemOwned — процесс принадлежит root (Unix) или системной учётной записи (Windows).
+func systemOwned(p *process.Process) bool {
+	if uids, err := p.Uids(); err == nil && len(uids) > 0 {
+		return uids[0] == 0
+	}
+	name, err := p.Username()
+	if err != nil {
+		return false
+	}
+	name = strings.ToUpper(name)
+	return strings.HasPrefix(name, `NT AUTHORITY\`) || name == "SYSTEM"
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// PF_KTHREAD в поле flags /proc/<pid>/stat.
+const pfKthread = 0x00200000
+
+// isKernelThread — поток ядра (kthreadd и его потомки).
+func isKernelThread(p *process.Process) bool {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", p.Pid))
+	if err != nil {
+		return false
+	}
+	// Имя в скобках может содержать пробелы — поля считаем после последней ')'.
+	s := string(b)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return false
+	}
+	fields := strings.Fields(s[i+1:])
+	// После имени: state ppid pgrp session tty_nr tpgid flags.
+	if len(fields) < 7 {
+		return false
+	}
+	flags, err := strconv.ParseUint(fields[6], 10, 64)
+	return err == nil && flags&pfKthread != 0
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package monitor
+
+import "github.com/shirou/gopsutil/v3/process"
+
+// Потоки ядра как отдельные процессы видны только в Linux.
+func isKernelThread(*process.Process) bool { return false }
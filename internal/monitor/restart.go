@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// OutcomeRestarted — процесс завершён и запущен заново (RestartResult.Outcome).
+const OutcomeRestarted = "restarted"
+
+// RestartResult — итог перезапуска процесса.
+type RestartResult struct {
+	PID     int32    `json:"pid"`
+	NewPID  int32    `json:"new_pid,omitempty"`
+	Outcome string   `json:"outcome"`
+	Exe     string   `json:"exe,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Cwd     string   `json:"cwd,omitempty"`
+	EnvVars int      `json:"env_vars"` // сколько переменных окружения передано (значения не возвращаются)
+	// CwdInherited и EnvInherited — рабочий каталог или окружение процесса прочитать нельзя,
+	// передан каталог или окружение Eye (только с inherit).
+	CwdInherited bool          `json:"cwd_inherited,omitempty"`
+	EnvInherited bool          `json:"env_inherited,omitempty"`
+	Terminate    *SignalResult `json:"terminate,omitempty"`
+	ElapsedMs    int64         `json:"elapsed_ms"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// OK сообщает, что процесс перезапущен.
+func (r RestartResult) OK() bool { return r.Outcome == OutcomeRestarted }
+
+// launchSpec — всё, что нужно для повторного запуска процесса.
+type launchSpec struct {
+	exe  string
+	args []string
+	cwd  string
+	env  []string
+	attr *syscall.SysProcAttr
+	// Каталог или окружение не прочитались, вместо них — Eye.
+	cwdInherited, envInherited bool
+}
+
+// RestartProcess перезапускает процесс с теми же exe, argv, рабочим каталогом и окружением:
+// сначала всё это читается (если не вышло — процесс не трогается), затем процесс мягко
+// завершается (как TerminateProcess с grace) и запускается заново отдельно от Eye.
+// Рабочий каталог и окружение не всегда можно прочитать (на Windows — у чужих процессов,
+// на части платформ окружение вообще): тогда перезапуск отказывает, а с inherit процесс
+// запускается в каталоге и с окружением Eye.
+func RestartProcess(ctx context.Context, pid int32, grace time.Duration, inherit bool) (res RestartResult) {
+	start := time.Now()
+	res = RestartResult{PID: pid}
+	defer func() { res.ElapsedMs = time.Since(start).Milliseconds() }()
+	fail := func(outcome string, err error) RestartResult {
+		res.Outcome, res.Error = outcome, err.Error()
+		return res
+	}
+
+	spec, err := captureLaunchSpec(pid, inherit)
+	if err != nil {
+		if errors.Is(err, ErrProcessNotFound) {
+			return fail(OutcomeNotFound, err)
+		}
+		return fail(classifySignalError(err), err)
+	}
+	res.Exe, res.Args, res.Cwd = spec.exe, spec.args, spec.cwd
+	res.EnvVars, res.CwdInherited, res.EnvInherited = len(spec.env), spec.cwdInherited, spec.envInherited
+
+	term := TerminateProcess(ctx, pid, grace, nil)
+	term.Progress = nil
+	res.Terminate = &term
+	if term.Outcome != OutcomeExited {
+		// Процесс не завершился — второй экземпляр не запускаем.
+		res.Outcome, res.Error = term.Outcome, term.Error
+		if res.Error == "" {
+			res.Error = "process did not exit"
+		}
+		return res
+	}
+
+	newPID, err := spec.launch()
+	if err != nil {
+		return fail(OutcomeError, fmt.Errorf("process exited but relaunch failed: %w", err))
+	}
+	res.NewPID, res.Outcome = newPID, OutcomeRestarted
+	return res
+}
+
+// captureLaunchSpec читает, как запустить процесс pid заново. Если рабочий каталог или
+// окружение не прочитать, без inherit — ошибка (errors.ErrUnsupported), с inherit —
+// берутся каталог и окружение Eye.
+func captureLaunchSpec(pid int32, inherit bool) (launchSpec, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return launchSpec{}, ErrProcessNotFound
+	}
+	var spec launchSpec
+	if spec.exe, err = p.Exe(); err != nil {
+		return spec, fmt.Errorf("read exe: %w", err)
+	}
+	// Бинарник пересобрали, пока процесс работал: запускаем новый файл по тому же пути.
+	spec.exe = strings.TrimSuffix(spec.exe, " (deleted)")
+	if spec.args, err = p.CmdlineSlice(); err != nil {
+		return spec, fmt.Errorf("read cmdline: %w", err)
+	}
+	if len(spec.args) == 0 {
+		return spec, fmt.Errorf("%w: process has no command line (kernel thread or zombie)", errors.ErrUnsupported)
+	}
+	// На Windows gopsutil возвращает пустой каталог без ошибки, если прав не хватило.
+	spec.cwd, err = p.Cwd()
+	switch {
+	case err != nil && !notImplemented(err):
+		return spec, fmt.Errorf("read cwd: %w", err)
+	case spec.cwd == "" && !inherit:
+		return spec, fmt.Errorf("%w: working directory of the process cannot be read; restart with inherit to use Eye's directory and environment", errors.ErrUnsupported)
+	case spec.cwd == "":
+		spec.cwd, _ = os.Getwd()
+		spec.cwdInherited = true
+	}
+	// Пользователя читаем сейчас: после завершения процесса его уже не узнать.
+	if spec.attr, err = detachedAttr(p); err != nil {
+		return spec, fmt.Errorf("read credentials: %w", err)
+	}
+	spec.env, err = p.Environ()
+	switch {
+	case err != nil && !notImplemented(err):
+		return spec, fmt.Errorf("read environment: %w", err)
+	case err != nil && !inherit:
+		return spec, fmt.Errorf("%w: environment of the process cannot be read on this platform; restart with inherit to use Eye's directory and environment", errors.ErrUnsupported)
+	case err != nil:
+		spec.env = os.Environ()
+		spec.envInherited = true
+	}
+	return spec, nil
+}
+
+// launch запускает процесс отдельно от Eye (своя сессия, ввод-вывод в никуда) и не ждёт его.
+func (s launchSpec) launch() (int32, error) {
+	cmd := &exec.Cmd{Path: s.exe, Args: s.args, Dir: s.cwd, Env: s.env, SysProcAttr: s.attr}
+	null, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer null.Close()
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = null, null, null
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	// Забираем статус завершения, чтобы не оставлять зомби, пока Eye работает.
+	go func() { _ = cmd.Wait() }()
+	return int32(cmd.Process.Pid), nil
+}
+
+// notImplemented — gopsutil не умеет это на текущей платформе. Его ErrNotImplementedError
+// лежит во внутреннем пакете, поэтому сравниваем по тексту.
+func notImplemented(err error) bool {
+	return err != nil && err.Error() == "not implemented yet"
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// detachedAttr — запуск в новой сессии. Если Eye работает от root, а процесс
+// принадлежал другому пользователю, новый процесс запускается от того же пользователя.
+func detachedAttr(p *process.Process) (*syscall.SysProcAttr, error) {
+	attr := &syscall.SysProcAttr{Setsid: true}
+	if os.Geteuid() == 0 {
+		uids, err := p.Uids()
+		if err != nil {
+			return nil, err
+		}
+		gids, err := p.Gids()
+		if err != nil {
+			return nil, err
+		}
+		// Uids/Gids: real, effective, saved, fs.
+		if len(uids) > 1 && len(gids) > 1 && uids[1] != 0 {
+			// Без списка групп процесс унаследовал бы дополнительные группы root — не запускаем.
+			groups, err := p.Groups()
+			if err != nil {
+				return nil, fmt.Errorf("read groups: %w", err)
+			}
+			cred := &syscall.Credential{Uid: uint32(uids[1]), Gid: uint32(gids[1]), Groups: []uint32{}}
+			for _, g := range groups {
+				cred.Groups = append(cred.Groups, uint32(g))
+			}
+			attr.Credential = cred
+		}
+	}
+	return attr, nil
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package monitor
+
+import (
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/windows"
+)
+
+// detachedAttr — запуск без консоли Eye и в своей группе процессов,
+// чтобы Ctrl+C и закрытие Eye его не затрагивали.
+func detachedAttr(_ *process.Process) (*syscall.SysProcAttr, error) {
+	return &syscall.SysProcAttr{
+		CreationFlags: windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dnsCacheSize     = 4096
+	dnsPositiveTTL   = 10 * time.Minute
+	dnsNegativeTTL   = time.Minute
+	dnsLookupTimeout = 2 * time.Second
+	dnsMaxInFlight   = 4
+)
+
+// reverseDNS — кэш обратных DNS-запросов. lookup никогда не ждёт сеть: отдаёт то, что
+// уже известно, а неизвестные адреса резолвит в фоне (не больше dnsMaxInFlight одновременно).
+type reverseDNS struct {
+	resolver *net.Resolver
+
+	mu       sync.Mutex
+	entries  map[string]dnsEntry
+	inFlight int
+}
+
+type dnsEntry struct {
+	host    string // пусто — имени нет
+	expires time.Time
+	pending bool
+}
+
+func newReverseDNS() *reverseDNS {
+	return &reverseDNS{resolver: net.DefaultResolver, entries: make(map[string]dnsEntry)}
+}
+
+// lookup возвращает имя для ip из кэша; если его нет — запускает фоновый запрос и возвращает "".
+func (d *reverseDNS) lookup(ip string) string {
+	if parsed := net.ParseIP(ip); parsed == nil || parsed.IsUnspecified() {
+		return ""
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.entries[ip]
+	if ok && (e.pending || time.Now().Before(e.expires)) {
+		return e.host
+	}
+	if d.inFlight >= dnsMaxInFlight {
+		// Очередь не копим: адрес попробуем при следующем запросе.
+		return e.host
+	}
+	if len(d.entries) >= dnsCacheSize {
+		d.evictLocked()
+	}
+	d.entries[ip] = dnsEntry{host: e.host, pending: true}
+	d.inFlight++
+	go d.resolve(ip)
+	return e.host
+}
+
+func (d *reverseDNS) resolve(ip string) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLookupTimeout)
+	names, err := d.resolver.LookupAddr(ctx, ip)
+	cancel()
+
+	e := dnsEntry{expires: time.Now().Add(dnsNegativeTTL)}
+	if err == nil && len(names) > 0 {
+		e.host = strings.TrimSuffix(names[0], ".")
+		e.expires = time.Now().Add(dnsPositiveTTL)
+	}
+	d.mu.Lock()
+	d.entries[ip] = e
+	d.inFlight--
+	d.mu.Unlock()
+}
+
+// evictLocked удаляет просроченные записи, а если таких нет — самую старую.
+func (d *reverseDNS) evictLocked() {
+	now := time.Now()
+	var oldest string
+	var oldestExp time.Time
+	for ip, e := range d.entries {
+		if e.pending {
+			continue
+		}
+		if now.After(e.expires) {
+			delete(d.entries, ip)
+			continue
+		}
+		if oldest == "" || e.expires.Before(oldestExp) {
+			oldest, oldestExp = ip, e.expires
+		}
+	}
+	if len(d.entries) >= dnsCacheSize && oldest != "" {
+		delete(d.entries, oldest)
+	}
+}
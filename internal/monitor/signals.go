@@ -0,0 +1,255 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Исходы операций над процессом (SignalResult.Outcome).
+const (
+	OutcomeExited           = "exited"
+	OutcomeStillRunning     = "still_running"
+	OutcomeSuspended        = "suspended"
+	OutcomeResumed          = "resumed"
+	OutcomePermissionDenied = "permission_denied"
+	OutcomeNotFound         = "not_found"
+	OutcomeNotSupported     = "not_supported"
+	OutcomeInvalidSignal    = "invalid_signal"
+	OutcomeError            = "error"
+)
+
+const (
+	// После SIGKILL ждём исчезновения процесса не дольше этого.
+	killWait = 2 * time.Second
+	// После произвольного сигнала — короткая пауза, чтобы отличить "завершился" от "работает".
+	signalSettle = 300 * time.Millisecond
+	pollInterval = 100 * time.Millisecond
+	// MaxTerminateGrace — верхняя граница grace в TerminateProcess, чтобы запрос terminate
+	// не висел бесконечно.
+	MaxTerminateGrace = 5 * time.Minute
+)
+
+// errNoGracefulClose — процесс нельзя попросить завершиться (на Windows у него нет окна,
+// которому послать WM_CLOSE). TerminateProcess в этом случае ждёт grace и завершает его
+// принудительно.
+var errNoGracefulClose = errors.New("process cannot be asked to exit")
+
+// SignalProgress — шаг длительной операции (terminate) для отображения прогресса.
+type SignalProgress struct {
+	PID         int32  `json:"pid"`
+	Stage       string `json:"stage"` // sigterm_sent / sigterm_unsupported / waiting / sigkill_sent / done
+	ElapsedMs   int64  `json:"elapsed_ms"`
+	RemainingMs int64  `json:"remaining_ms,omitempty"`
+}
+
+// SignalResult — итог операции над процессом.
+type SignalResult struct {
+	PID       int32            `json:"pid"`
+	Action    string           `json:"action"` // terminate / suspend / resume / signal
+	Signal    string           `json:"signal,omitempty"`
+	Outcome   string           `json:"outcome"`
+	Escalated bool             `json:"escalated,omitempty"` // понадобился SIGKILL
+	ElapsedMs int64            `json:"elapsed_ms"`
+	Error     string           `json:"error,omitempty"`
+	Progress  []SignalProgress `json:"progress,omitempty"`
+}
+
+// OK сообщает, достигнут ли ожидаемый результат операции.
+func (r SignalResult) OK() bool {
+	switch r.Outcome {
+	case OutcomeExited, OutcomeSuspended, OutcomeResumed:
+		return true
+	case OutcomeStillRunning:
+		// Для произвольного сигнала работающий процесс — нормальный исход (например, SIGHUP).
+		return r.Action == "signal"
+	}
+	return false
+}
+
+// processIdentity — PID плюс время старта, чтобы не перепутать процесс с новым на том же PID.
+type processIdentity struct {
+	pid        int32
+	createTime int64
+}
+
+func identify(pid int32) (processIdentity, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return processIdentity{}, err
+	}
+	ct, _ := p.CreateTime()
+	return processIdentity{pid: pid, createTime: ct}, nil
+}
+
+// alive — процесс существует, это тот же процесс и он не зомби.
+func (id processIdentity) alive() bool {
+	p, err := process.NewProcess(id.pid)
+	if err != nil {
+		return false
+	}
+	if ct, err := p.CreateTime(); err == nil && id.createTime != 0 && !sameCreateTime(ct, id.createTime) {
+		return false
+	}
+	if status, err := p.Status(); err == nil && len(status) > 0 && status[0] == process.Zombie {
+		return false
+	}
+	return true
+}
+
+// waitExit ждёт исчезновения процесса до таймаута или отмены ctx.
+func (id processIdentity) waitExit(ctx context.Context, timeout time.Duration, tick func(remaining time.Duration)) bool {
+	deadline := time.Now().Add(timeout)
+	lastTick := time.Now()
+	for {
+		if !id.alive() {
+			return true
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		if tick != nil && time.Since(lastTick) >= time.Second {
+			tick(remaining)
+			lastTick = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return !id.alive()
+		case <-time.After(min(pollInterval, remaining)):
+		}
+	}
+}
+
+// classifySignalError переводит ошибку отправки сигнала в Outcome.
+func classifySignalError(err error) string {
+	switch {
+	case errors.Is(err, os.ErrPermission), errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		return OutcomePermissionDenied
+	case errors.Is(err, os.ErrProcessDone), errors.Is(err, syscall.ESRCH), errors.Is(err, process.ErrorProcessNotRunning):
+		return OutcomeNotFound
+	case errors.Is(err, errors.ErrUnsupported), errors.Is(err, errNoGracefulClose):
+		return OutcomeNotSupported
+	}
+	return OutcomeError
+}
+
+func failResult(res SignalResult, outcome string, err error) SignalResult {
+	res.Outcome = outcome
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// TerminateProcess мягко завершает процесс: SIGTERM (на Windows — запрос закрытия),
+// ожидание grace (не больше MaxTerminateGrace) и SIGKILL, если процесс не завершился.
+// progress (может быть nil) вызывается на каждом шаге.
+func TerminateProcess(ctx context.Context, pid int32, grace time.Duration, progress func(SignalProgress)) (res SignalResult) {
+	if grace < 0 || grace > MaxTerminateGrace {
+		// Отрицательный grace — переполнение при переводе огромного grace_ms.
+		grace = MaxTerminateGrace
+	}
+	start := time.Now()
+	res = SignalResult{PID: pid, Action: "terminate", Signal: "SIGTERM"}
+	report := func(stage string, remaining time.Duration) {
+		p := SignalProgress{PID: pid, Stage: stage, ElapsedMs: time.Since(start).Milliseconds(), RemainingMs: remaining.Milliseconds()}
+		res.Progress = append(res.Progress, p)
+		if progress != nil {
+			progress(p)
+		}
+	}
+	defer func() { res.ElapsedMs = time.Since(start).Milliseconds() }()
+
+	id, err := identify(pid)
+	if err != nil {
+		return failResult(res, OutcomeNotFound, err)
+	}
+	switch err := sendSignal(pid, "SIGTERM"); {
+	case errors.Is(err, errNoGracefulClose):
+		report("sigterm_unsupported", grace)
+	case err != nil:
+		return failResult(res, classifySignalError(err), err)
+	default:
+		report("sigterm_sent", grace)
+	}
+
+	if id.waitExit(ctx, grace, func(remaining time.Duration) { report("waiting", remaining) }) {
+		res.Outcome = OutcomeExited
+		report("done", 0)
+		return res
+	}
+	if ctx.Err() != nil {
+		return failResult(res, OutcomeStillRunning, ctx.Err())
+	}
+
+	res.Escalated = true
+	if err := sendSignal(pid, "SIGKILL"); err != nil && id.alive() {
+		return failResult(res, classifySignalError(err), err)
+	}
+	report("sigkill_sent", killWait)
+	if id.waitExit(ctx, killWait, nil) {
+		res.Outcome = OutcomeExited
+	} else {
+		res.Outcome = OutcomeStillRunning
+		res.Error = fmt.Sprintf("process %d still running after SIGKILL", pid)
+	}
+	report("done", 0)
+	return res
+}
+
+// SuspendProcess приостанавливает процесс (SIGSTOP; на Windows — NtSuspendProcess).
+func SuspendProcess(pid int32) SignalResult {
+	return changeRunState(pid, "suspend", "SIGSTOP", OutcomeSuspended)
+}
+
+// ResumeProcess продолжает приостановленный процесс (SIGCONT; на Windows — NtResumeProcess).
+func ResumeProcess(pid int32) SignalResult {
+	return changeRunState(pid, "resume", "SIGCONT", OutcomeResumed)
+}
+
+func changeRunState(pid int32, action, sig, outcome string) SignalResult {
+	start := time.Now()
+	res := SignalResult{PID: pid, Action: action, Signal: sig}
+	if _, err := identify(pid); err != nil {
+		return failResult(res, OutcomeNotFound, err)
+	}
+	if err := sendSignal(pid, sig); err != nil {
+		res = failResult(res, classifySignalError(err), err)
+	} else {
+		res.Outcome = outcome
+	}
+	res.ElapsedMs = time.Since(start).Milliseconds()
+	return res
+}
+
+// SignalProcess отправляет процессу сигнал по имени ("TERM", "SIGHUP", "9")
+// и сообщает, завершился ли процесс после него.
+func SignalProcess(pid int32, name string) SignalResult {
+	start := time.Now()
+	res := SignalResult{PID: pid, Action: "signal", Signal: name}
+	sig, err := normalizeSignal(name)
+	if err != nil {
+		return failResult(res, OutcomeInvalidSignal, err)
+	}
+	res.Signal = sig
+	id, err := identify(pid)
+	if err != nil {
+		return failResult(res, OutcomeNotFound, err)
+	}
+	if err := sendSignal(pid, sig); err != nil {
+		return failResult(res, classifySignalError(err), err)
+	}
+	if id.waitExit(context.Background(), signalSettle, nil) {
+		res.Outcome = OutcomeExited
+	} else {
+		res.Outcome = OutcomeStillRunning
+	}
+	res.ElapsedMs = time.Since(start).Milliseconds()
+	return res
+}
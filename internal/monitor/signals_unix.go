@@ -0,0 +1,38 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// normalizeSignal приводит "term", "SIGTERM" или "15" к каноническому имени "SIGTERM".
+func normalizeSignal(name string) (string, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if n, err := strconv.Atoi(name); err == nil {
+		if s := unix.SignalName(syscall.Signal(n)); s != "" {
+			return s, nil
+		}
+		return "", fmt.Errorf("unknown signal %d", n)
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if unix.SignalNum(name) == 0 {
+		return "", fmt.Errorf("unknown signal %q", name)
+	}
+	return name, nil
+}
+
+func sendSignal(pid int32, name string) error {
+	sig := unix.SignalNum(name)
+	if sig == 0 {
+		return fmt.Errorf("unknown signal %q", name)
+	}
+	return unix.Kill(int(pid), sig)
+}
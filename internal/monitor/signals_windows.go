@@ -0,0 +1,79 @@
+//go:build windows
+
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/windows"
+)
+
+// На Windows сигналов нет — поддерживаем их аналоги:
+// SIGTERM — taskkill без /F (WM_CLOSE), SIGKILL — TerminateProcess, SIGSTOP/SIGCONT — NtSuspend/ResumeProcess.
+var windowsSignals = map[string]bool{"SIGTERM": true, "SIGKILL": true, "SIGSTOP": true, "SIGCONT": true}
+
+func normalizeSignal(name string) (string, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	switch name {
+	case "15":
+		name = "SIGTERM"
+	case "9":
+		name = "SIGKILL"
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if !windowsSignals[name] {
+		return "", fmt.Errorf("signal %s: %w", name, errors.ErrUnsupported)
+	}
+	return name, nil
+}
+
+func sendSignal(pid int32, name string) error {
+	if name == "SIGTERM" {
+		return closeProcess(pid)
+	}
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "SIGKILL":
+		return p.Kill()
+	case "SIGSTOP":
+		return p.Suspend()
+	case "SIGCONT":
+		return p.Resume()
+	}
+	return fmt.Errorf("signal %s: %w", name, errors.ErrUnsupported)
+}
+
+// closeProcess просит процесс завершиться (taskkill без /F). Права проверяются заранее
+// через OpenProcess: текст ошибки taskkill зависит от языка системы, а код выхода у всех
+// ошибок один. Если права есть, а taskkill не справился, — у процесса нет окна
+// (errNoGracefulClose).
+func closeProcess(pid int32) error {
+	h, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, uint32(pid))
+	switch {
+	case errors.Is(err, windows.ERROR_ACCESS_DENIED):
+		return fmt.Errorf("pid %d: %w", pid, os.ErrPermission)
+	case errors.Is(err, windows.ERROR_INVALID_PARAMETER):
+		return fmt.Errorf("pid %d: %w", pid, os.ErrProcessDone)
+	case err != nil:
+		return err
+	}
+	windows.CloseHandle(h)
+
+	cmd := exec.Command("taskkill", "/PID", strconv.Itoa(int(pid)))
+	setProcessNoWindow(cmd)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("taskkill: %s: %w", strings.TrimSpace(string(out)), errNoGracefulClose)
+	}
+	return nil
+}
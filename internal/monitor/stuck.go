@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// StuckProcess — процесс-зомби, в непрерываемом сне (D) или остановленный.
+type StuckProcess struct {
+	PID        int32  `json:"pid"`
+	PPID       int32  `json:"ppid"`
+	Name       string `json:"name"`
+	State      string `json:"state"` // zombie, blocked, stop
+	Username   string `json:"username,omitempty"`
+	Cmdline    string `json:"cmdline,omitempty"`
+	ParentName string `json:"parent_name,omitempty"` // только для зомби: кто не забрал статус
+	// Wchan — функция ядра, в которой процесс ждёт; пусто, если ядро её не показывает.
+	Wchan string `json:"wchan,omitempty"`
+	// Since — когда Eye впервые увидел процесс в этом состоянии (unix, секунды).
+	Since       int64   `json:"since"`
+	DurationSec float64 `json:"duration_sec"`
+	// LowerBound — состояние было уже при запуске Eye, на самом деле оно длится дольше.
+	LowerBound bool `json:"lower_bound,omitempty"`
+}
+
+// ZombieParent — процесс, у которого есть незабранные потомки-зомби.
+type ZombieParent struct {
+	PID     int32  `json:"pid"`
+	Name    string `json:"name,omitempty"`
+	Zombies int    `json:"zombies"`
+}
+
+// StuckCounts — число процессов в каждом из «плохих» состояний.
+type StuckCounts struct {
+	Zombie  int `json:"zombie"`
+	Blocked int `json:"blocked"`
+	Stopped int `json:"stopped"`
+}
+
+// StuckReport — отчёт о зависших процессах по последнему тику коллектора.
+type StuckReport struct {
+	StuckCounts
+	Time      int64          `json:"time"`      // unix, секунды
+	Processes []StuckProcess `json:"processes"` // по убыванию длительности
+	// ZombieParents — родители зомби по убыванию их числа: обычно чинить надо именно их.
+	ZombieParents []ZombieParent `json:"zombie_parents"`
+}
+
+// stuckState сообщает, считается ли состояние «плохим».
+func stuckState(status string) bool {
+	return status == process.Zombie || status == process.Blocked || status == process.Stop
+}
+
+func countStuck(snaps []processSnapshot) StuckCounts {
+	var n StuckCounts
+	for _, s := range snaps {
+		switch s.info.Status {
+		case process.Zombie:
+			n.Zombie++
+		case process.Blocked:
+			n.Blocked++
+		case process.Stop:
+			n.Stopped++
+		}
+	}
+	return n
+}
+
+// StuckProcesses возвращает зомби, процессы в D и остановленные процессы. В список попадают
+// только те, что находятся в состоянии не меньше minAge (счётчики — по всем). Длительность
+// считается по тикам коллектора: состояние, сменившееся и вернувшееся между тиками, не заметить.
+func (c *Collector) StuckProcesses(minAge time.Duration) StuckReport {
+	snaps := c.processSnapshots()
+	now := time.Now()
+	r := StuckReport{
+		StuckCounts:   countStuck(snaps),
+		Time:          now.Unix(),
+		Processes:     []StuckProcess{},
+		ZombieParents: []ZombieParent{},
+	}
+	names := make(map[int32]string, len(snaps))
+	for _, s := range snaps {
+		names[s.info.PID] = s.info.Name
+	}
+	parents := make(map[int32]int)
+	for _, s := range snaps {
+		if !stuckState(s.info.Status) {
+			continue
+		}
+		if s.info.Status == process.Zombie {
+			parents[s.ppid]++
+		}
+		age := now.Sub(s.stateSince)
+		if age < minAge {
+			continue
+		}
+		sp := StuckProcess{
+			PID:         s.info.PID,
+			PPID:        s.ppid,
+			Name:        s.info.Name,
+			State:       s.info.Status,
+			Username:    s.info.Username,
+			Wchan:       readWchan(s.info.PID),
+			Since:       s.stateSince.Unix(),
+			DurationSec: age.Seconds(),
+			LowerBound:  s.stateFirst,
+		}
+		if s.info.Status == process.Zombie {
+			sp.ParentName = names[s.ppid]
+		} else {
+			sp.Cmdline, _ = s.proc.Cmdline()
+		}
+		r.Processes = append(r.Processes, sp)
+	}
+	sort.Slice(r.Processes, func(i, j int) bool {
+		a, b := r.Processes[i], r.Processes[j]
+		if a.DurationSec != b.DurationSec {
+			return a.DurationSec > b.DurationSec
+		}
+		return a.PID < b.PID
+	})
+	for pid, n := range parents {
+		r.ZombieParents = append(r.ZombieParents, ZombieParent{PID: pid, Name: names[pid], Zombies: n})
+	}
+	sort.Slice(r.ZombieParents, func(i, j int) bool {
+		a, b := r.ZombieParents[i], r.ZombieParents[j]
+		if a.Zombies != b.Zombies {
+			return a.Zombies > b.Zombies
+		}
+		return a.PID < b.PID
+	})
+	return r
+}
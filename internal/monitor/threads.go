@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// threadMinInterval — минимальный интервал между замерами потоков: на более коротком
+	// CPU% слишком шумный, поэтому запрос ждёт недостающее время.
+	threadMinInterval = 250 * time.Millisecond
+	// threadFirstInterval — интервал первого замера процесса (предыдущего нет).
+	threadFirstInterval = 500 * time.Millisecond
+	// threadHistoryTTL — сколько помнить прошлый замер процесса без запросов.
+	threadHistoryTTL = 5 * time.Minute
+)
+
+// ThreadInfo — поток процесса.
+type ThreadInfo struct {
+	TID        int32   `json:"tid"`
+	Name       string  `json:"name"`  // comm потока
+	State      string  `json:"state"` // running, sleep, blocked (D), stop, zombie, idle
+	CPUPercent float64 `json:"cpu_percent"`
+	UserSec    float64 `json:"user_sec"` // накопленное время с запуска потока
+	SystemSec  float64 `json:"system_sec"`
+	Processor  int     `json:"processor"`          // CPU, на котором поток выполнялся последним
+	Affinity   string  `json:"affinity,omitempty"` // список CPU, например "0-3"
+	StartTime  int64   `json:"start_time,omitempty"`
+}
+
+// ThreadList — потоки процесса; CPUPercent посчитан за IntervalMs с прошлого замера.
+type ThreadList struct {
+	PID        int32        `json:"pid"`
+	IntervalMs int64        `json:"interval_ms"`
+	Threads    []ThreadInfo `json:"threads"` // по убыванию CPU%, затем по TID
+}
+
+// threadSample — поток при чтении /proc/<pid>/task.
+type threadSample struct {
+	info      ThreadInfo
+	startTick uint64  // starttime в тиках: отличает поток от нового с тем же TID
+	cpu       float64 // user+system, секунды
+}
+
+// threadSampler помнит процессорное время потоков с прошлого запроса каждого процесса,
+// чтобы CPU% потока считался за интервал, как CPU% процесса в processTable.
+type threadSampler struct {
+	mu    sync.Mutex
+	procs map[int32]*threadHistory
+}
+
+type threadHistory struct {
+	at      time.Time
+	threads map[int32]threadSample
+}
+
+func newThreadSampler() *threadSampler {
+	return &threadSampler{procs: make(map[int32]*threadHistory)}
+}
+
+// ProcessThreads возвращает потоки процесса pid с CPU% за интервал с прошлого запроса.
+// Если прошлого замера нет, делает два замера с паузой threadFirstInterval.
+func (c *Collector) ProcessThreads(pid int32) (ThreadList, error) {
+	prev := c.threads.previous(pid)
+	if prev == nil {
+		threads, err := readThreads(pid)
+		if err != nil {
+			return ThreadList{}, err
+		}
+		prev = &threadHistory{at: time.Now(), threads: threads}
+		time.Sleep(threadFirstInterval)
+	} else if wait := threadMinInterval - time.Since(prev.at); wait > 0 {
+		time.Sleep(wait)
+	}
+	threads, err := readThreads(pid)
+	if err != nil {
+		c.threads.store(pid, nil)
+		return ThreadList{}, err
+	}
+	now := time.Now()
+	sec := now.Sub(prev.at).Seconds()
+	list := ThreadList{PID: pid, IntervalMs: now.Sub(prev.at).Milliseconds(), Threads: make([]ThreadInfo, 0, len(threads))}
+	for tid, t := range threads {
+		old, ok := prev.threads[tid]
+		// Потока не было в прошлом замере — его время росло с нуля.
+		fromStart := !ok || old.startTick != t.startTick
+		// 100% = одно ядро, как у процессов.
+		t.info.CPUPercent = intervalRate(t.cpu, old.cpu, sec, fromStart) * 100
+		list.Threads = append(list.Threads, t.info)
+	}
+	c.threads.store(pid, &threadHistory{at: now, threads: threads})
+
+	sort.Slice(list.Threads, func(i, j int) bool {
+		a, b := list.Threads[i], list.Threads[j]
+		if a.CPUPercent != b.CPUPercent {
+			return a.CPUPercent > b.CPUPercent
+		}
+		return a.TID < b.TID
+	})
+	return list, nil
+}
+
+// previous возвращает прошлый замер процесса и выбрасывает устаревшие замеры.
+func (ts *threadSampler) previous(pid int32) *threadHistory {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for p, h := range ts.procs {
+		if time.Since(h.at) > threadHistoryTTL {
+			delete(ts.procs, p)
+		}
+	}
+	return ts.procs[pid]
+}
+
+// store запоминает замер процесса; nil — забыть процесс.
+func (ts *threadSampler) store(pid int32, h *threadHistory) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if h == nil {
+		delete(ts.procs, pid)
+		return
+	}
+	ts.procs[pid] = h
+}
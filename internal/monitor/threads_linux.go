@@ -0,0 +1,101 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// clockTicks — USER_HZ: в /proc время всегда в сотых долях секунды.
+const clockTicks = 100
+
+// readThreads читает потоки процесса из /proc/<pid>/task/<tid>/stat.
+func readThreads(pid int32) (map[int32]threadSample, error) {
+	dir := "/proc/" + strconv.Itoa(int(pid)) + "/task"
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrProcessNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	boot, _ := host.BootTime()
+	out := make(map[int32]threadSample, len(entries))
+	for _, e := range entries {
+		tid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(dir + "/" + e.Name() + "/stat")
+		if err != nil {
+			continue // поток завершился
+		}
+		t, ok := parseThreadStat(int32(tid), data)
+		if !ok {
+			continue
+		}
+		t.info.Affinity = readAffinity(tid)
+		if boot > 0 {
+			t.info.StartTime = int64(boot + t.startTick/clockTicks)
+		}
+		out[int32(tid)] = t
+	}
+	return out, nil
+}
+
+// parseThreadStat разбирает stat потока. comm в скобках может содержать пробелы и скобки,
+// поэтому поля считаются от последней ')'.
+func parseThreadStat(tid int32, data []byte) (threadSample, bool) {
+	open, end := bytes.IndexByte(data, '('), bytes.LastIndexByte(data, ')')
+	if open < 0 || end < open {
+		return threadSample{}, false
+	}
+	// fields[0] — поле 3 (state), значит поле N — fields[N-3].
+	fields := bytes.Fields(data[end+1:])
+	if len(fields) < 37 {
+		return threadSample{}, false
+	}
+	num := func(n int) uint64 {
+		v, _ := strconv.ParseUint(string(fields[n-3]), 10, 64)
+		return v
+	}
+	utime, stime := float64(num(14))/clockTicks, float64(num(15))/clockTicks
+	t := threadSample{
+		info: ThreadInfo{
+			TID:       tid,
+			Name:      string(data[open+1 : end]),
+			State:     threadState(string(fields[0])),
+			UserSec:   utime,
+			SystemSec: stime,
+			Processor: int(num(39)),
+		},
+		startTick: num(22),
+		cpu:       utime + stime,
+	}
+	return t, true
+}
+
+func threadState(letter string) string {
+	switch letter {
+	case "R":
+		return process.Running
+	case "S":
+		return process.Sleep
+	case "D":
+		return process.Blocked
+	case "T", "t":
+		return process.Stop
+	case "Z":
+		return process.Zombie
+	case "I":
+		return process.Idle
+	}
+	return letter
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package monitor
+
+import "errors"
+
+// readThreads — потоки с их временем и состоянием читаются только из /proc Linux.
+func readThreads(int32) (map[int32]threadSample, error) {
+	return nil, errors.ErrUnsupported
+}
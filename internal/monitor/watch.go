@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessIdentity — то, по чему процесс находят в списке наблюдения.
+type ProcessIdentity struct {
+	PID       int32  `json:"pid"`
+	Name      string `json:"name"`
+	Exe       string `json:"exe,omitempty"`
+	Cmdline   string `json:"cmdline,omitempty"`
+	Username  string `json:"username,omitempty"`
+	StartTime int64  `json:"start_time,omitempty"` // unix, секунды
+}
+
+// ProcessUsage — потребление процесса за последний тик коллектора.
+type ProcessUsage struct {
+	PID          int32   `json:"pid"`
+	CPUPercent   float64 `json:"cpu_percent"`
+	RSSMB        uint64  `json:"rss_mb"`
+	Threads      int32   `json:"threads,omitempty"`
+	FDs          int32   `json:"fds,omitempty"` // 0 — нет прав или платформа не поддерживает
+	DiskReadBps  float64 `json:"disk_read_bps,omitempty"`
+	DiskWriteBps float64 `json:"disk_write_bps,omitempty"`
+}
+
+// identityCache помнит exe и cmdline процессов: они не меняются за жизнь процесса,
+// а читать их для всех процессов на каждом замере дорого.
+type identityCache struct {
+	mu      sync.Mutex
+	entries map[int32]cachedIdentity
+}
+
+type cachedIdentity struct {
+	startTime    int64
+	exe, cmdline string
+}
+
+func newIdentityCache() *identityCache {
+	return &identityCache{entries: make(map[int32]cachedIdentity)}
+}
+
+// ProcessIdentities возвращает процессы последнего тика с путём к исполняемому файлу
+// и командной строкой. Для каждого процесса они читаются один раз.
+func (c *Collector) ProcessIdentities() []ProcessIdentity {
+	snaps := c.processSnapshots()
+	idents := c.identities(snaps)
+	out := make([]ProcessIdentity, 0, len(snaps))
+	for _, s := range snaps {
+		e := idents[s.info.PID]
+		out = append(out, ProcessIdentity{
+			PID:       s.info.PID,
+			Name:      s.info.Name,
+			Exe:       e.exe,
+			Cmdline:   e.cmdline,
+			Username:  s.info.Username,
+			StartTime: s.info.StartTime,
+		})
+	}
+	return out
+}
+
+// identities возвращает exe и cmdline процессов снимка, дочитывая в кэш только новые.
+func (c *Collector) identities(snaps []processSnapshot) map[int32]cachedIdentity {
+	ic := c.idents
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	out := make(map[int32]cachedIdentity, len(snaps))
+	for _, s := range snaps {
+		e, ok := ic.entries[s.info.PID]
+		if !ok || e.startTime != s.info.StartTime {
+			e = cachedIdentity{startTime: s.info.StartTime}
+			e.exe, _ = s.proc.Exe()
+			e.cmdline, _ = s.proc.Cmdline()
+			ic.entries[s.info.PID] = e
+		}
+		out[s.info.PID] = e
+	}
+	for pid := range ic.entries {
+		if _, ok := out[pid]; !ok {
+			delete(ic.entries, pid)
+		}
+	}
+	return out
+}
+
+// ProcessUsage возвращает потребление процессов pids по последнему тику; потоки и
+// дескрипторы читаются сразу. Процессов, которых уже нет, в результате нет.
+func (c *Collector) ProcessUsage(pids []int32) []ProcessUsage {
+	want := make(map[int32]UsageMetrics, len(pids))
+	for _, pid := range pids {
+		want[pid] = UsageMetrics{Threads: true, FDs: true}
+	}
+	return c.ProcessUsageOf(want)
+}
+
+// UsageMetrics — какие метрики ProcessUsageOf читает сверх снимка коллектора. Потоки и
+// дескрипторы читаются отдельным обращением к системе для каждого процесса, поэтому их
+// стоит запрашивать, только если они нужны.
+type UsageMetrics struct {
+	Threads bool
+	FDs     bool
+}
+
+// Union возвращает метрики, нужные m или o.
+func (m UsageMetrics) Union(o UsageMetrics) UsageMetrics {
+	return UsageMetrics{Threads: m.Threads || o.Threads, FDs: m.FDs || o.FDs}
+}
+
+// ProcessUsageOf — как ProcessUsage, но потоки и дескрипторы процесса читаются, только
+// если они запрошены для его PID в want.
+func (c *Collector) ProcessUsageOf(want map[int32]UsageMetrics) []ProcessUsage {
+	var out []ProcessUsage
+	for _, s := range c.processSnapshots() {
+		m, ok := want[s.info.PID]
+		if !ok {
+			continue
+		}
+		u := ProcessUsage{
+			PID:          s.info.PID,
+			CPUPercent:   s.info.CPUPercent,
+			RSSMB:        s.info.RSSMB,
+			DiskReadBps:  s.info.DiskReadBps,
+			DiskWriteBps: s.info.DiskWriteBps,
+		}
+		if m.Threads {
+			u.Threads, _ = s.proc.NumThreads()
+		}
+		if m.FDs {
+			u.FDs, _ = s.proc.NumFDs()
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+// LookupProcess читает имя, exe и командную строку процесса pid напрямую (без снимка коллектора).
+func LookupProcess(pid int32) (ProcessIdentity, bool) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return ProcessIdentity{PID: pid}, false
+	}
+	id := ProcessIdentity{PID: pid, Name: processName(p)}
+	id.Exe, _ = p.Exe()
+	id.Cmdline, _ = p.Cmdline()
+	id.Username, _ = p.Username()
+	if ct, err := p.CreateTime(); err == nil {
+		id.StartTime = ct / 1000
+	}
+	return id, true
+}
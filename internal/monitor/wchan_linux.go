@@ -0,0 +1,23 @@
+//go:build linux
+
+package monitor
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readWchan читает /proc/<pid>/wchan. При kptr_restrict или без прав ядро отдаёт "0" —
+// это то же, что «не показывает».
+func readWchan(pid int32) string {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/wchan")
+	if err != nil {
+		return ""
+	}
+	w := strings.TrimSpace(string(b))
+	if w == "0" {
+		return ""
+	}
+	return w
+}
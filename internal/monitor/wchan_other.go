@@ -0,0 +1,6 @@
+//go:build !linux
+
+package monitor
+
+// readWchan — на других платформах функция ожидания ядра недоступна.
+func readWchan(int32) string { return "" }
@@ -0,0 +1,955 @@
+package rules
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/store"
+)
+
+const (
+	fileName        = "rules.json"
+	firingsFileName = "rule_firings.jsonl"
+	// Правила проверяются раз в evalInterval по последнему тику коллектора.
+	evalInterval = 2 * time.Second
+	// maxFirings — сколько срабатываний помнить; до стольких же сжимается файл срабатываний.
+	maxFirings      = 1000
+	defaultCooldown = 5 * time.Minute
+	maxForSec       = 24 * 60 * 60
+)
+
+// Действия правила (Rule.Action).
+const (
+	ActionNotify    = "notify"    // только срабатывание: WebSocket и топик Hub
+	ActionRenice    = "renice"    // nice из Rule.Nice
+	ActionSuspend   = "suspend"   // SIGSTOP
+	ActionTerminate = "terminate" // SIGTERM, через GraceMs — SIGKILL
+)
+
+// Метрики условий (Condition.Metric) — значения из последнего тика коллектора.
+const (
+	MetricCPU       = "cpu" // %, 100 = одно ядро
+	MetricRSS       = "rss" // МБ
+	MetricThreads   = "threads"
+	MetricFDs       = "fds"
+	MetricDiskRead  = "disk_read" // байт/с
+	MetricDiskWrite = "disk_write"
+)
+
+// Результаты срабатывания (Firing.Result), кроме audit.Result* и Outcome* из monitor.
+const (
+	ResultNotified = "notified"
+	ResultDryRun   = "dry_run"
+)
+
+var (
+	ErrNotFound = fmt.Errorf("rule %w", store.ErrNotFound)
+	ErrInvalid  = fmt.Errorf("%w rule", store.ErrInvalid)
+)
+
+// ConfirmationError — правило с confirmed, которое будет приостанавливать или завершать
+// процессы, сохраняется только с токеном подтверждения. Authorization содержит новый токен
+// (привязан к правилу: действию, Match, условиям и ForSec) и защищённые процессы, которые
+// подходят под правило сейчас.
+type ConfirmationError struct {
+	Authorization monitor.Authorization
+}
+
+func (e *ConfirmationError) Error() string { return "confirmation required" }
+
+// Match — какие процессы проверяет правило; заданные поля должны совпасть все.
+type Match struct {
+	Name    string `json:"name,omitempty"`    // имя процесса или файла, без учёта регистра
+	Cmdline string `json:"cmdline,omitempty"` // регулярное выражение
+	User    string `json:"user,omitempty"`
+}
+
+// Condition — порог метрики процесса: Metric Op Value, например rss > 4096.
+type Condition struct {
+	Metric string  `json:"metric"`
+	Op     string  `json:"op"` // ">" или "<"
+	Value  float64 `json:"value"`
+}
+
+func (c Condition) holds(v float64) bool {
+	if c.Op == "<" {
+		return v < c.Value
+	}
+	return v > c.Value
+}
+
+// Rule — правило, хранится в data-dir/rules.json. Срабатывает для процесса, когда все
+// условия выполняются непрерывно ForSec секунд, и затем не чаще раза в CooldownSec.
+type Rule struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name,omitempty"`
+	Enabled    bool        `json:"enabled"`
+	Match      Match       `json:"match"`
+	Conditions []Condition `json:"conditions"`
+	ForSec     int         `json:"for_sec"`
+	Action     string      `json:"action"`
+	Nice       *int        `json:"nice,omitempty"`     // для renice
+	GraceMs    int         `json:"grace_ms,omitempty"` // для terminate; 0 — из настроек
+	// CooldownSec — пауза между срабатываниями для одного процесса; 0 — 5 минут.
+	CooldownSec int `json:"cooldown_sec,omitempty"`
+	// DryRun — только записывать срабатывания, действие не выполнять.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Confirmed — автор правила заранее подтвердил renice, suspend и terminate для процессов, которым
+	// защита требует подтверждения (protected_names, системные пользователи). Такое правило
+	// сохраняется только с токеном подтверждения (см. ConfirmationError). Запрещённые
+	// защитой процессы правила не трогают никогда.
+	Confirmed bool  `json:"confirmed,omitempty"`
+	CreatedAt int64 `json:"created_at"` // unix, секунды
+}
+
+// Title — подпись правила.
+func (r Rule) Title() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Action + " " + r.Match.String()
+}
+
+func (m Match) String() string {
+	var parts []string
+	if m.Name != "" {
+		parts = append(parts, "name="+m.Name)
+	}
+	if m.Cmdline != "" {
+		parts = append(parts, "cmdline~"+m.Cmdline)
+	}
+	if m.User != "" {
+		parts = append(parts, "user="+m.User)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r Rule) cooldown() time.Duration {
+	if r.CooldownSec > 0 {
+		return time.Duration(r.CooldownSec) * time.Second
+	}
+	return defaultCooldown
+}
+
+func (r Rule) validate() error {
+	if r.Match.Name == "" && r.Match.Cmdline == "" && r.Match.User == "" {
+		return fmt.Errorf("%w: match needs name, cmdline or user", ErrInvalid)
+	}
+	if _, err := regexp.Compile(r.Match.Cmdline); err != nil {
+		return fmt.Errorf("%w: cmdline: %v", ErrInvalid, err)
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("%w: at least one condition is required", ErrInvalid)
+	}
+	for _, c := range r.Conditions {
+		switch c.Metric {
+		case MetricCPU, MetricRSS, MetricThreads, MetricFDs, MetricDiskRead, MetricDiskWrite:
+		default:
+			return fmt.Errorf("%w: unknown metric %q", ErrInvalid, c.Metric)
+		}
+		if c.Op != ">" && c.Op != "<" {
+			return fmt.Errorf("%w: op must be > or <", ErrInvalid)
+		}
+		if c.Value < 0 {
+			return fmt.Errorf("%w: %s: value must not be negative", ErrInvalid, c.Metric)
+		}
+	}
+	if r.ForSec < 0 || r.ForSec > maxForSec {
+		return fmt.Errorf("%w: for_sec must be 0..%d", ErrInvalid, maxForSec)
+	}
+	switch r.Action {
+	case ActionNotify, ActionSuspend:
+	case ActionRenice:
+		if r.Nice == nil || *r.Nice < -20 || *r.Nice > 19 {
+			return fmt.Errorf("%w: renice needs nice in -20..19", ErrInvalid)
+		}
+	case ActionTerminate:
+		if r.GraceMs < 0 || int64(r.GraceMs) > monitor.MaxTerminateGrace.Milliseconds() {
+			return fmt.Errorf("%w: grace_ms must be in 0..%d", ErrInvalid, monitor.MaxTerminateGrace.Milliseconds())
+		}
+	default:
+		return fmt.Errorf("%w: action must be notify, renice, suspend or terminate", ErrInvalid)
+	}
+	if r.CooldownSec < 0 {
+		return fmt.Errorf("%w: cooldown_sec must not be negative", ErrInvalid)
+	}
+	return nil
+}
+
+// Evidence — значения метрики условия, на которых правило сработало.
+type Evidence struct {
+	Condition
+	Current float64 `json:"current"`
+	// Min и Max — за всё время, пока условия выполнялись.
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Firing — срабатывание правила для процесса.
+type Firing struct {
+	ID       uint64     `json:"id"`
+	Time     int64      `json:"time"` // unix, мс
+	RuleID   string     `json:"rule_id"`
+	RuleName string     `json:"rule_name"`
+	Action   string     `json:"action"`
+	DryRun   bool       `json:"dry_run,omitempty"`
+	PID      int32      `json:"pid"`
+	Name     string     `json:"name"`
+	Cmdline  string     `json:"cmdline,omitempty"`
+	Username string     `json:"username,omitempty"`
+	Since    int64      `json:"since"` // с какого момента выполнялись условия, unix, мс
+	Samples  int        `json:"samples"`
+	Evidence []Evidence `json:"evidence"`
+	Result   string     `json:"result"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Pending — процесс, у которого условия правила уже выполняются, но ForSec ещё не прошло
+// (или идёт пауза после срабатывания).
+type Pending struct {
+	PID   int32  `json:"pid"`
+	Name  string `json:"name"`
+	Since int64  `json:"since"` // unix, мс
+}
+
+// Status — правило с состоянием проверки.
+type Status struct {
+	Rule
+	Matching   int       `json:"matching"` // сколько процессов подходит под Match
+	Pending    []Pending `json:"pending,omitempty"`
+	Firings    int       `json:"firings"` // с момента старта Eye
+	LastFiring *Firing   `json:"last_firing,omitempty"`
+}
+
+// Candidate — процесс, подходящий под правило, с текущими значениями метрик (для Test).
+type Candidate struct {
+	PID    int32              `json:"pid"`
+	Name   string             `json:"name"`
+	Values map[string]float64 `json:"values"`
+	// Holds — условия выполняются прямо сейчас (без учёта ForSec).
+	Holds bool `json:"holds"`
+	// Protection — решение защиты процессов для renice, suspend и terminate.
+	Protection *monitor.ProtectionCheck `json:"protection,omitempty"`
+}
+
+// FiringFilter — выборка срабатываний.
+type FiringFilter struct {
+	RuleID  string
+	AfterID uint64
+	Since   time.Time
+	Limit   int // последние Limit; 0 — все
+}
+
+// Config — зависимости Engine.
+type Config struct {
+	DataDir   string
+	Collector *monitor.Collector
+	// Guard проверяет renice, suspend и terminate; процессы, которым нужно подтверждение,
+	// правила трогают только с Rule.Confirmed. Он же выдаёт токены для сохранения таких правил.
+	Guard *monitor.ProcessGuard
+	Audit *audit.Log
+	// TerminateGrace — grace по умолчанию для terminate (Rule.GraceMs = 0).
+	TerminateGrace func() time.Duration
+}
+
+// trackKey — процесс под правилом; время старта отличает процесс от нового с тем же PID.
+type trackKey struct {
+	rule  string
+	pid   int32
+	start int64
+}
+
+// track — сколько условия правила выполняются для процесса.
+type track struct {
+	name     string
+	since    time.Time
+	min, max []float64
+	samples  int
+}
+
+// job — срабатывание, действие которого ещё выполняется.
+type job struct {
+	key    trackKey
+	rule   Rule
+	firing Firing
+}
+
+// Engine проверяет правила по замерам коллектора и выполняет их действия.
+type Engine struct {
+	cfg         Config
+	path        string
+	firingsPath string
+
+	mu        sync.Mutex
+	rules     []Rule
+	res       map[string]*regexp.Regexp // Match.Cmdline по ID правила
+	tracks    map[trackKey]*track
+	cooldowns map[trackKey]time.Time
+	matching  map[string]int
+	counts    map[string]int
+	firings   []Firing
+	nextID    uint64
+	subs      map[chan Firing]struct{}
+	// running — процессы, действие над которыми ещё выполняется (terminate ждёт grace).
+	running map[trackKey]bool
+	// fileLines — сколько срабатываний в файле; при 2×maxFirings файл сжимается.
+	fileLines int
+}
+
+// Load читает правила и последние срабатывания из cfg.DataDir; если файлов нет — правил нет.
+// Испорченные правила пропускаются и перечисляются в ошибке, исходный файл сохраняется рядом.
+func Load(cfg Config) (*Engine, error) {
+	if cfg.TerminateGrace == nil {
+		cfg.TerminateGrace = func() time.Duration { return 5 * time.Second }
+	}
+	e := &Engine{
+		cfg:         cfg,
+		path:        filepath.Join(cfg.DataDir, fileName),
+		firingsPath: filepath.Join(cfg.DataDir, firingsFileName),
+		rules:       []Rule{},
+		res:         make(map[string]*regexp.Regexp),
+		tracks:      make(map[trackKey]*track),
+		cooldowns:   make(map[trackKey]time.Time),
+		running:     make(map[trackKey]bool),
+		matching:    make(map[string]int),
+		counts:      make(map[string]int),
+		subs:        make(map[chan Firing]struct{}),
+	}
+	firingsErr := e.loadFirings()
+	rules, err := store.LoadList(e.path, Rule.validate)
+	for _, r := range rules {
+		e.rules = append(e.rules, r)
+		e.res[r.ID] = compileMatch(r)
+	}
+	return e, errors.Join(err, firingsErr)
+}
+
+func compileMatch(r Rule) *regexp.Regexp {
+	if r.Match.Cmdline == "" {
+		return nil
+	}
+	return regexp.MustCompile(r.Match.Cmdline)
+}
+
+// Run проверяет правила раз в evalInterval, пока ctx не отменён.
+func (e *Engine) Run(ctx context.Context) {
+	t := time.NewTicker(evalInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			for _, j := range e.evaluate(time.Now()) {
+				// terminate ждёт до grace — не задерживаем следующую проверку.
+				go e.fire(ctx, j)
+			}
+		}
+	}
+}
+
+// List возвращает правила с состоянием проверки.
+func (e *Engine) List() []Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]Status, 0, len(e.rules))
+	for _, r := range e.rules {
+		out = append(out, e.statusLocked(r))
+	}
+	return out
+}
+
+// Get возвращает правило id с состоянием проверки.
+func (e *Engine) Get(id string) (Status, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.indexLocked(id)
+	if i < 0 {
+		return Status{}, ErrNotFound
+	}
+	return e.statusLocked(e.rules[i]), nil
+}
+
+// Add добавляет правило; confirmToken нужен для правила с confirmed (см. ConfirmationError).
+func (e *Engine) Add(r Rule, confirmToken string) (Rule, error) {
+	r.CreatedAt = time.Now().Unix()
+	r.Name = strings.TrimSpace(r.Name)
+	if err := r.validate(); err != nil {
+		return Rule{}, err
+	}
+	if err := e.confirm(nil, r, confirmToken); err != nil {
+		return Rule{}, err
+	}
+	r.ID = store.NewID()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	next := append(slices.Clone(e.rules), r)
+	if err := e.save(next); err != nil {
+		return Rule{}, err
+	}
+	e.rules = next
+	e.res[r.ID] = compileMatch(r)
+	return r, nil
+}
+
+// Update применяет fn к копии правила id и сохраняет результат. ID и время создания
+// не меняются; состояние проверки правила начинается заново. confirmToken — как в Add.
+func (e *Engine) Update(id string, fn func(*Rule) error, confirmToken string) (Rule, error) {
+	e.mu.Lock()
+	i := e.indexLocked(id)
+	if i < 0 {
+		e.mu.Unlock()
+		return Rule{}, ErrNotFound
+	}
+	prev := e.rules[i]
+	e.mu.Unlock()
+
+	// fn (декодирование тела запроса) выполняется без блокировки: медленный клиент не должен
+	// задерживать проверку правил. JSON декодируется поверх копии, поэтому срез и указатель
+	// не должны указывать в сохранённое правило.
+	next := prev
+	next.Conditions = slices.Clone(prev.Conditions)
+	if prev.Nice != nil {
+		nice := *prev.Nice
+		next.Nice = &nice
+	}
+	if err := fn(&next); err != nil {
+		return Rule{}, err
+	}
+	next.ID, next.CreatedAt = prev.ID, prev.CreatedAt
+	next.Name = strings.TrimSpace(next.Name)
+	if err := next.validate(); err != nil {
+		return Rule{}, err
+	}
+	if err := e.confirm(&prev, next, confirmToken); err != nil {
+		return Rule{}, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i = e.indexLocked(id); i < 0 {
+		return Rule{}, ErrNotFound
+	}
+	rules := slices.Clone(e.rules)
+	rules[i] = next
+	if err := e.save(rules); err != nil {
+		return Rule{}, err
+	}
+	e.rules = rules
+	e.res[id] = compileMatch(next)
+	e.resetLocked(id)
+	return next, nil
+}
+
+// Delete удаляет правило id; его срабатывания остаются в журнале.
+func (e *Engine) Delete(id string) (Rule, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.indexLocked(id)
+	if i < 0 {
+		return Rule{}, ErrNotFound
+	}
+	r := e.rules[i]
+	next := slices.Delete(slices.Clone(e.rules), i, i+1)
+	if err := e.save(next); err != nil {
+		return Rule{}, err
+	}
+	e.rules = next
+	delete(e.res, id)
+	delete(e.matching, id)
+	e.resetLocked(id)
+	return r, nil
+}
+
+// guarded — действие правила меняет процесс и проверяется защитой процессов.
+func (r Rule) guarded() bool {
+	return r.Action == ActionRenice || r.Action == ActionSuspend || r.Action == ActionTerminate
+}
+
+// armed — правило будет само менять процессы, которым нужно подтверждение.
+func (r Rule) armed() bool {
+	return r.Confirmed && !r.DryRun && r.guarded()
+}
+
+// confirm требует токен подтверждения, если next становится armed или, будучи armed, меняет
+// действие, Match, условия или ForSec. Включение и выключение правила подтверждения не требует.
+func (e *Engine) confirm(prev *Rule, next Rule, token string) error {
+	if !next.armed() {
+		return nil
+	}
+	if prev != nil && prev.armed() && prev.Action == next.Action && prev.Match == next.Match &&
+		slices.Equal(prev.Conditions, next.Conditions) && prev.ForSec == next.ForSec {
+		return nil
+	}
+	subject, _ := json.Marshal(struct {
+		ID         string
+		Action     string
+		Match      Match
+		Conditions []Condition
+		ForSec     int
+	}{next.ID, next.Action, next.Match, next.Conditions, next.ForSec})
+	auth := e.cfg.Guard.AuthorizeSubject("rule."+next.Action, string(subject), token)
+	if auth.Allowed {
+		return nil
+	}
+	for _, id := range matchProcesses(next, compileMatch(next), e.cfg.Collector.ProcessIdentities()) {
+		if c := e.cfg.Guard.Check(id.PID); c.Decision != monitor.ProtectionAllow {
+			auth.Checks = append(auth.Checks, c)
+		}
+	}
+	return &ConfirmationError{Authorization: auth}
+}
+
+// Test проверяет правило r (не сохраняя его) по текущим процессам: какие подходят под Match,
+// их метрики и выполняются ли условия сейчас.
+func (e *Engine) Test(r Rule) ([]Candidate, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	idents := matchProcesses(r, compileMatch(r), e.cfg.Collector.ProcessIdentities())
+	usage := e.usage(map[string][]monitor.ProcessIdentity{r.ID: idents}, []Rule{r})
+	out := make([]Candidate, 0, len(idents))
+	for _, id := range idents {
+		u, ok := usage[id.PID]
+		if !ok {
+			continue
+		}
+		values, holds := measure(r.Conditions, u)
+		c := Candidate{PID: id.PID, Name: id.Name, Values: make(map[string]float64, len(values)), Holds: holds}
+		for i, cond := range r.Conditions {
+			c.Values[cond.Metric] = values[i]
+		}
+		if r.guarded() {
+			check := e.cfg.Guard.Check(id.PID)
+			c.Protection = &check
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// Firings возвращает срабатывания по фильтру, от старых к новым.
+func (e *Engine) Firings(f FiringFilter) []Firing {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]Firing, 0)
+	for _, fr := range e.firings {
+		if fr.ID <= f.AfterID || (f.RuleID != "" && fr.RuleID != f.RuleID) ||
+			(!f.Since.IsZero() && fr.Time < f.Since.UnixMilli()) {
+			continue
+		}
+		out = append(out, fr)
+	}
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[len(out)-f.Limit:]
+	}
+	return out
+}
+
+// Subscribe возвращает канал новых срабатываний и функцию отписки.
+// Медленный подписчик пропускает срабатывания, а не задерживает правила.
+func (e *Engine) Subscribe() (<-chan Firing, func()) {
+	ch := make(chan Firing, 16)
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.mu.Unlock()
+	return ch, func() {
+		e.mu.Lock()
+		delete(e.subs, ch)
+		e.mu.Unlock()
+	}
+}
+
+func (e *Engine) indexLocked(id string) int {
+	return slices.IndexFunc(e.rules, func(r Rule) bool { return r.ID == id })
+}
+
+func (e *Engine) statusLocked(r Rule) Status {
+	s := Status{Rule: r, Matching: e.matching[r.ID], Firings: e.counts[r.ID]}
+	for key, tr := range e.tracks {
+		if key.rule == r.ID {
+			s.Pending = append(s.Pending, Pending{PID: key.pid, Name: tr.name, Since: tr.since.UnixMilli()})
+		}
+	}
+	slices.SortFunc(s.Pending, func(a, b Pending) int { return cmp.Compare(a.PID, b.PID) })
+	for i := len(e.firings) - 1; i >= 0; i-- {
+		if e.firings[i].RuleID == r.ID {
+			last := e.firings[i]
+			s.LastFiring = &last
+			break
+		}
+	}
+	return s
+}
+
+// resetLocked забывает, сколько выполняются условия правила id.
+func (e *Engine) resetLocked(id string) {
+	for key := range e.tracks {
+		if key.rule == id {
+			delete(e.tracks, key)
+		}
+	}
+	for key := range e.cooldowns {
+		if key.rule == id {
+			delete(e.cooldowns, key)
+		}
+	}
+}
+
+// evaluate проверяет включённые правила по последнему тику коллектора и возвращает
+// срабатывания, действия которых нужно выполнить.
+func (e *Engine) evaluate(now time.Time) []job {
+	e.mu.Lock()
+	var active []Rule
+	for _, r := range e.rules {
+		if r.Enabled {
+			active = append(active, r)
+		}
+	}
+	res := make(map[string]*regexp.Regexp, len(active))
+	for _, r := range active {
+		res[r.ID] = e.res[r.ID]
+	}
+	e.mu.Unlock()
+
+	matched := make(map[string][]monitor.ProcessIdentity, len(active))
+	if len(active) > 0 {
+		idents := e.cfg.Collector.ProcessIdentities()
+		for _, r := range active {
+			matched[r.ID] = matchProcesses(r, res[r.ID], idents)
+		}
+	}
+	usage := e.usage(matched, active)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var jobs []job
+	seen := make(map[trackKey]bool)
+	for _, r := range active {
+		if e.indexLocked(r.ID) < 0 {
+			continue // правило удалили, пока шёл замер
+		}
+		e.matching[r.ID] = len(matched[r.ID])
+		for _, id := range matched[r.ID] {
+			u, ok := usage[id.PID]
+			if !ok {
+				continue
+			}
+			values, holds := measure(r.Conditions, u)
+			if !holds {
+				continue
+			}
+			key := trackKey{rule: r.ID, pid: id.PID, start: id.StartTime}
+			seen[key] = true
+			tr, ok := e.tracks[key]
+			if !ok {
+				tr = &track{name: id.Name, since: now, min: slices.Clone(values), max: slices.Clone(values)}
+				e.tracks[key] = tr
+			}
+			for i, v := range values {
+				tr.min[i], tr.max[i] = min(tr.min[i], v), max(tr.max[i], v)
+			}
+			tr.samples++
+			if now.Sub(tr.since) < time.Duration(r.ForSec)*time.Second {
+				continue
+			}
+			if until, ok := e.cooldowns[key]; ok && now.Before(until) {
+				continue
+			}
+			// Пауза короче grace: прошлый terminate ещё ждёт — второй не запускаем.
+			if e.running[key] {
+				continue
+			}
+			e.cooldowns[key] = now.Add(r.cooldown())
+			e.running[key] = true
+			jobs = append(jobs, job{key: key, rule: r, firing: newFiring(r, id, tr, values, now)})
+		}
+	}
+	for key := range e.tracks {
+		if !seen[key] {
+			delete(e.tracks, key)
+		}
+	}
+	for key, until := range e.cooldowns {
+		if now.After(until) {
+			delete(e.cooldowns, key)
+		}
+	}
+	for id := range e.matching {
+		if _, ok := matched[id]; !ok {
+			e.matching[id] = 0 // правило выключено
+		}
+	}
+	return jobs
+}
+
+func newFiring(r Rule, id monitor.ProcessIdentity, tr *track, values []float64, now time.Time) Firing {
+	f := Firing{
+		Time:     now.UnixMilli(),
+		RuleID:   r.ID,
+		RuleName: r.Title(),
+		Action:   r.Action,
+		DryRun:   r.DryRun,
+		PID:      id.PID,
+		Name:     id.Name,
+		Cmdline:  id.Cmdline,
+		Username: id.Username,
+		Since:    tr.since.UnixMilli(),
+		Samples:  tr.samples,
+		Evidence: make([]Evidence, 0, len(r.Conditions)),
+	}
+	for i, c := range r.Conditions {
+		f.Evidence = append(f.Evidence, Evidence{Condition: c, Current: values[i], Min: tr.min[i], Max: tr.max[i]})
+	}
+	return f
+}
+
+// usage — потребление процессов, подходящих под правила (matched по ID правила), с последнего
+// тика коллектора. Потоки и дескрипторы читаются только для процессов правил с такими условиями.
+func (e *Engine) usage(matched map[string][]monitor.ProcessIdentity, rules []Rule) map[int32]monitor.ProcessUsage {
+	want := make(map[int32]monitor.UsageMetrics)
+	for _, r := range rules {
+		m := r.metrics()
+		for _, id := range matched[r.ID] {
+			want[id.PID] = want[id.PID].Union(m)
+		}
+	}
+	out := make(map[int32]monitor.ProcessUsage, len(want))
+	if len(want) == 0 {
+		return out
+	}
+	for _, u := range e.cfg.Collector.ProcessUsageOf(want) {
+		out[u.PID] = u
+	}
+	return out
+}
+
+// metrics — какие метрики сверх снимка коллектора нужны условиям правила.
+func (r Rule) metrics() monitor.UsageMetrics {
+	var m monitor.UsageMetrics
+	for _, c := range r.Conditions {
+		m.Threads = m.Threads || c.Metric == MetricThreads
+		m.FDs = m.FDs || c.Metric == MetricFDs
+	}
+	return m
+}
+
+// matchProcesses возвращает процессы, подходящие под Match правила. Eye себя не проверяет.
+func matchProcesses(r Rule, re *regexp.Regexp, idents []monitor.ProcessIdentity) []monitor.ProcessIdentity {
+	self := int32(os.Getpid())
+	var out []monitor.ProcessIdentity
+	for _, id := range idents {
+		if id.PID == self {
+			continue
+		}
+		m := r.Match
+		if m.Name != "" && !strings.EqualFold(id.Name, m.Name) &&
+			(id.Exe == "" || !strings.EqualFold(filepath.Base(id.Exe), m.Name)) {
+			continue
+		}
+		if re != nil && (id.Cmdline == "" || !re.MatchString(id.Cmdline)) {
+			continue
+		}
+		if m.User != "" && !strings.EqualFold(id.Username, m.User) {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+// measure возвращает значения метрик условий и выполняются ли все условия.
+func measure(conds []Condition, u monitor.ProcessUsage) ([]float64, bool) {
+	values := make([]float64, len(conds))
+	holds := true
+	for i, c := range conds {
+		switch c.Metric {
+		case MetricCPU:
+			values[i] = u.CPUPercent
+		case MetricRSS:
+			values[i] = float64(u.RSSMB)
+		case MetricThreads:
+			values[i] = float64(u.Threads)
+		case MetricFDs:
+			values[i] = float64(u.FDs)
+		case MetricDiskRead:
+			values[i] = u.DiskReadBps
+		case MetricDiskWrite:
+			values[i] = u.DiskWriteBps
+		}
+		holds = holds && c.holds(values[i])
+	}
+	return values, holds
+}
+
+// fire выполняет действие срабатывания (если это не dry-run) и записывает срабатывание.
+func (e *Engine) fire(ctx context.Context, j job) {
+	r, f := j.rule, j.firing
+	e.execute(ctx, r, &f)
+	e.mu.Lock()
+	delete(e.running, j.key)
+	e.nextID++
+	f.ID = e.nextID
+	e.firings = append(e.firings, f)
+	if len(e.firings) > maxFirings+maxFirings/8 {
+		e.firings = append(e.firings[:0:0], e.firings[len(e.firings)-maxFirings:]...)
+	}
+	e.counts[r.ID]++
+	for ch := range e.subs {
+		select {
+		case ch <- f:
+		default:
+		}
+	}
+	e.mu.Unlock()
+	e.appendFiring(f)
+}
+
+func (e *Engine) execute(ctx context.Context, r Rule, f *Firing) {
+	if r.DryRun {
+		f.Result = ResultDryRun
+		return
+	}
+	if r.Action == ActionNotify {
+		f.Result = ResultNotified
+		return
+	}
+	entry := audit.Entry{
+		Action:  r.Action,
+		Origin:  audit.OriginRule,
+		PID:     f.PID,
+		Name:    f.Name,
+		Cmdline: f.Cmdline,
+		Details: map[string]string{"rule": r.ID, "rule_name": r.Title()},
+	}
+	defer func() {
+		entry.Result, entry.Error = f.Result, f.Error
+		_ = e.cfg.Audit.Record(entry)
+	}()
+
+	if r.guarded() {
+		switch c := e.cfg.Guard.Check(f.PID); c.Decision {
+		case monitor.ProtectionDeny:
+			f.Result, f.Error = audit.ResultDenied, c.Reason
+			return
+		case monitor.ProtectionConfirm:
+			if !r.Confirmed {
+				f.Result, f.Error = audit.ResultConfirmationRequired, c.Reason+"; set confirmed on the rule to act on such processes"
+				return
+			}
+			entry.Details["confirmed"] = "true"
+		}
+	}
+	switch r.Action {
+	case ActionRenice:
+		entry.Action = "priority"
+		entry.Details["nice"] = strconv.Itoa(*r.Nice)
+		res := monitor.SetProcessPriority(f.PID, monitor.PriorityChange{Nice: r.Nice})
+		f.Result, f.Error = res.Outcome, res.Error
+	case ActionSuspend:
+		res := monitor.SuspendProcess(f.PID)
+		f.Result, f.Error = res.Outcome, res.Error
+	case ActionTerminate:
+		grace := e.cfg.TerminateGrace()
+		if r.GraceMs > 0 {
+			grace = time.Duration(r.GraceMs) * time.Millisecond
+		}
+		res := monitor.TerminateProcess(ctx, f.PID, grace, nil)
+		f.Result, f.Error = res.Outcome, res.Error
+		if res.Escalated {
+			entry.Details["escalated"] = "true"
+		}
+	}
+}
+
+// loadFirings читает последние maxFirings срабатываний; если в файле их больше — переписывает его.
+func (e *Engine) loadFirings() error {
+	file, err := os.Open(e.firingsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var f Firing
+		if json.Unmarshal(sc.Bytes(), &f) != nil {
+			continue
+		}
+		e.fileLines++
+		e.firings = append(e.firings, f)
+		if len(e.firings) > maxFirings {
+			e.firings = e.firings[1:]
+		}
+		e.nextID = max(e.nextID, f.ID)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if e.fileLines > maxFirings {
+		return e.compactLocked()
+	}
+	return nil
+}
+
+// compactLocked переписывает файл срабатываний теми, что помнятся в памяти.
+func (e *Engine) compactLocked() error {
+	var b []byte
+	for _, f := range e.firings {
+		line, _ := json.Marshal(f)
+		b = append(append(b, line...), '\n')
+	}
+	if err := store.WriteFile(e.firingsPath, b, 0600); err != nil {
+		return err
+	}
+	e.fileLines = len(e.firings)
+	return nil
+}
+
+// appendFiring дописывает срабатывание в файл. В нём командные строки процессов,
+// поэтому файл читает только владелец.
+func (e *Engine) appendFiring(f Firing) {
+	line, err := json.Marshal(f)
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.fileLines >= 2*maxFirings {
+		// Срабатывание уже в памяти — сжатие его и запишет.
+		_ = e.compactLocked()
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(e.firingsPath), 0755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(e.firingsPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err == nil {
+		e.fileLines++
+	}
+}
+
+func (e *Engine) save(rules []Rule) error {
+	return store.SaveJSON(e.path, rules, 0644)
+}
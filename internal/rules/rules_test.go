@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+)
+
+func TestMeasure(t *testing.T) {
+	u := monitor.ProcessUsage{CPUPercent: 150, RSSMB: 4096, Threads: 64, FDs: 900, DiskReadBps: 1e6, DiskWriteBps: 5e5}
+	tests := []struct {
+		name   string
+		conds  []Condition
+		values []float64
+		holds  bool
+	}{
+		{"cpu above", []Condition{{MetricCPU, ">", 100}}, []float64{150}, true},
+		{"cpu below", []Condition{{MetricCPU, "<", 100}}, []float64{150}, false},
+		{"equal is not above", []Condition{{MetricRSS, ">", 4096}}, []float64{4096}, false},
+		{"all hold", []Condition{{MetricRSS, ">", 1024}, {MetricThreads, ">", 10}, {MetricFDs, "<", 1000}}, []float64{4096, 64, 900}, true},
+		{"one fails", []Condition{{MetricDiskRead, ">", 1e5}, {MetricDiskWrite, ">", 1e6}}, []float64{1e6, 5e5}, false},
+		{"no conditions", nil, []float64{}, true},
+	}
+	for _, tt := range tests {
+		values, holds := measure(tt.conds, u)
+		if !slices.Equal(values, tt.values) || holds != tt.holds {
+			t.Errorf("%s: measure = %v, %v; want %v, %v", tt.name, values, holds, tt.values, tt.holds)
+		}
+	}
+}
+
+func TestMatchProcesses(t *testing.T) {
+	idents := []monitor.ProcessIdentity{
+		{PID: 10, Name: "node", Exe: "/usr/bin/node", Cmdline: "node server.js --port 80", Username: "www"},
+		{PID: 11, Name: "MainThread", Exe: "/opt/app/Chrome", Cmdline: "/opt/app/Chrome --type=renderer", Username: "alice"},
+		{PID: 12, Name: "python3", Cmdline: "", Username: "alice"},
+	}
+	tests := []struct {
+		name  string
+		match Match
+		want  []int32
+	}{
+		{"any", Match{}, []int32{10, 11, 12}},
+		{"name ignores case", Match{Name: "NODE"}, []int32{10}},
+		{"name from exe", Match{Name: "chrome"}, []int32{11}},
+		{"cmdline regexp", Match{Cmdline: `--type=\w+`}, []int32{11}},
+		{"empty cmdline never matches", Match{Cmdline: `.*`}, []int32{10, 11}},
+		{"user", Match{User: "Alice"}, []int32{11, 12}},
+		{"name and user", Match{Name: "node", User: "alice"}, nil},
+	}
+	for _, tt := range tests {
+		var re *regexp.Regexp
+		if tt.match.Cmdline != "" {
+			re = regexp.MustCompile(tt.match.Cmdline)
+		}
+		var got []int32
+		for _, id := range matchProcesses(Rule{Match: tt.match}, re, idents) {
+			got = append(got, id.PID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: matched %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArmed(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want bool
+	}{
+		{Rule{Action: ActionTerminate, Confirmed: true}, true},
+		{Rule{Action: ActionSuspend, Confirmed: true}, true},
+		{Rule{Action: ActionRenice, Confirmed: true}, true},
+		{Rule{Action: ActionNotify, Confirmed: true}, false},
+		{Rule{Action: ActionTerminate, Confirmed: true, DryRun: true}, false},
+		{Rule{Action: ActionTerminate}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.armed(); got != tt.want {
+			t.Errorf("armed(%s, confirmed %v, dry run %v) = %v, want %v",
+				tt.rule.Action, tt.rule.Confirmed, tt.rule.DryRun, got, tt.want)
+		}
+	}
+}
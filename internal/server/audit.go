@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+)
+
+// recordHTTP дописывает запись с источником — HTTP-клиентом запроса r.
+func recordHTTP(alog *audit.Log, r *http.Request, e audit.Entry) {
+	e.Origin, e.Remote = audit.OriginHTTP, r.RemoteAddr
+	_ = alog.Record(e)
+}
+
+// withSignalResult заполняет результат записи по исходу операции над процессом.
+func withSignalResult(e audit.Entry, res monitor.SignalResult) audit.Entry {
+	e.Result, e.Error = res.Outcome, res.Error
+	if res.Signal != "" || res.Escalated {
+		e.Details = map[string]string{}
+		if res.Signal != "" {
+			e.Details["signal"] = res.Signal
+		}
+		if res.Escalated {
+			e.Details["escalated"] = "true"
+		}
+	}
+	return e
+}
+
+// withError заполняет результат записи: ok или error с текстом.
+func withError(e audit.Entry, err error) audit.Entry {
+	e.Result = audit.ResultOK
+	if err != nil {
+		e.Result, e.Error = audit.ResultError, err.Error()
+	}
+	return e
+}
+
+func registerAuditRoutes(srv *coreserver.Server, alog *audit.Log) {
+	// GET /api/audit — журнал изменяющих действий, от старых к новым.
+	// since, until — unix-секунды; action — через запятую (watchlist — все watchlist.*, rules — все rules.*,
+	// snapshots — все snapshots.*); origin=http|hub|rule, pid, limit (по умолчанию 500 последних).
+	srv.Mux.HandleFunc("GET /api/audit", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		v := r.URL.Query()
+		f := audit.Filter{Origin: v.Get("origin"), Limit: 500}
+		if s := v.Get("action"); s != "" {
+			f.Actions = strings.Split(s, ",")
+		}
+		for name, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+			if s := v.Get(name); s != "" {
+				sec, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, "invalid "+name)
+					return
+				}
+				*dst = time.Unix(sec, 0)
+			}
+		}
+		if s := v.Get("pid"); s != "" {
+			pid, err := strconv.ParseInt(s, 10, 32)
+			if err != nil || pid <= 0 {
+				writeError(w, http.StatusBadRequest, "invalid pid")
+				return
+			}
+			f.PID = int32(pid)
+		}
+		if s := v.Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			f.Limit = n
+		}
+		entries, err := alog.Query(f)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+}
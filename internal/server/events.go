@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+)
+
+// processEventsMessage — сообщение WebSocket (/ws) с новыми событиями процессов.
+type processEventsMessage struct {
+	Type   string                 `json:"type"` // "process_events"
+	Events []monitor.ProcessEvent `json:"events"`
+}
+
+func registerEventRoutes(srv *coreserver.Server, collector *monitor.Collector) {
+	// GET /api/processes/events — журнал запусков и завершений процессов.
+	// after=<id> — только новее (для опроса), since=<unix> — с момента, type=start|exit, name, limit,
+	// summary=1 — добавить группировку по имени процесса.
+	srv.Mux.HandleFunc("GET /api/processes/events", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		v := r.URL.Query()
+		f := monitor.ProcessEventFilter{Type: v.Get("type"), Name: v.Get("name"), Limit: 500}
+		if f.Type != "" && f.Type != monitor.ProcessEventStart && f.Type != monitor.ProcessEventExit {
+			writeError(w, http.StatusBadRequest, "type must be start or exit")
+			return
+		}
+		if s := v.Get("after"); s != "" {
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid after")
+				return
+			}
+			f.AfterID = id
+		}
+		if s := v.Get("since"); s != "" {
+			sec, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid since")
+				return
+			}
+			f.Since = time.Unix(sec, 0)
+		}
+		if s := v.Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			f.Limit = n
+		}
+		events := collector.ProcessEvents(f)
+		resp := map[string]interface{}{"events": events}
+		if len(events) > 0 {
+			resp["last_id"] = events[len(events)-1].ID
+		}
+		if queryFlag(r, "summary") {
+			resp["summary"] = monitor.SummarizeProcessEvents(events)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	// Пуш новых событий всем клиентам WebSocket. /ws из nekkus-core принимает соединения
+	// с любой страницы, поэтому командные строки (в них бывают токены и пароли) туда не идут:
+	// полностью события читаются через GET /api/processes/events?after=<id>.
+	go func() {
+		events, _ := collector.SubscribeProcessEvents()
+		for batch := range events {
+			// Пачка общая для всех подписчиков — меняем копию.
+			batch = slices.Clone(batch)
+			for i := range batch {
+				batch[i].Cmdline = ""
+			}
+			srv.Broadcast(processEventsMessage{Type: "process_events", Events: batch})
+		}
+	}()
+}
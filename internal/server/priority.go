@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+)
+
+func registerPriorityRoutes(srv *coreserver.Server, guard *monitor.ProcessGuard, alog *audit.Log) {
+	// POST /api/processes/priority — {"pid": 123, "nice": 10, "affinity": "0-3", "io_class": "idle", "tree": true}.
+	// Возвращает итоговые значения; с tree — по результату на каждый процесс поддерева.
+	// Защита проверяет каждый процесс (с tree — всё дерево), как для terminate; dry_run и
+	// confirm_token — как там же.
+	srv.Mux.HandleFunc("POST /api/processes/priority", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		var body struct {
+			monitor.PriorityChange
+			PID  int32 `json:"pid"`
+			Tree bool  `json:"tree"`
+			guardFields
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		if body.PID <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid pid")
+			return
+		}
+		if err := body.PriorityChange.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		pids := []int32{body.PID}
+		if body.Tree {
+			var err error
+			pids, err = monitor.ProcessTreePIDs(body.PID)
+			if errors.Is(err, monitor.ErrProcessNotFound) {
+				writeError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		target := audit.Target("priority", body.PID)
+		target.Details = priorityDetails(body.PriorityChange, body.Tree)
+		if !authorize(w, r, guard, alog, target, pids, body.guardFields) {
+			return
+		}
+		if !body.Tree {
+			res := monitor.SetProcessPriority(body.PID, body.PriorityChange)
+			target.Result, target.Error = res.Outcome, res.Error
+			recordHTTP(alog, r, target)
+			if !res.OK() {
+				w.WriteHeader(outcomeStatus(res.Outcome))
+			}
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+
+		results := monitor.SetProcessListPriority(pids, body.PriorityChange)
+		target.Result, target.Error = results[0].Outcome, results[0].Error
+		target.Details["processes"] = strconv.Itoa(len(results))
+		recordHTTP(alog, r, target)
+		ok := true
+		for _, res := range results {
+			ok = ok && res.OK()
+		}
+		// Код ответа — по корню дерева; ошибки потомков видны в results.
+		if !results[0].OK() {
+			w.WriteHeader(outcomeStatus(results[0].Outcome))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": ok, "results": results})
+	})
+}
+
+// priorityDetails — изменённые поля приоритета для журнала аудита.
+func priorityDetails(ch monitor.PriorityChange, tree bool) map[string]string {
+	d := map[string]string{}
+	if ch.Nice != nil {
+		d["nice"] = strconv.Itoa(*ch.Nice)
+	}
+	if ch.Affinity != "" {
+		d["affinity"] = ch.Affinity
+	}
+	if ch.IOClass != "" {
+		d["io_class"] = ch.IOClass
+	}
+	if ch.IOLevel != nil {
+		d["io_level"] = strconv.Itoa(*ch.IOLevel)
+	}
+	if tree {
+		d["tree"] = "true"
+	}
+	return d
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+)
+
+// guardFields — общие поля тел запросов, которые завершают или останавливают процессы.
+type guardFields struct {
+	// DryRun — только проверить защиту и показать, что было бы сделано.
+	DryRun bool `json:"dry_run,omitempty"`
+	// ConfirmToken — токен из ответа 428 для защищённых процессов.
+	ConfirmToken string `json:"confirm_token,omitempty"`
+}
+
+// authorize проверяет действие target.Action над pids защитой процессов. Если выполнять нельзя
+// (запрещено — 403, нужно подтверждение — 428) или это dry-run, сам пишет ответ и возвращает false.
+// Отклонённые попытки попадают в журнал аудита.
+func authorize(w http.ResponseWriter, r *http.Request, guard *monitor.ProcessGuard, alog *audit.Log,
+	target audit.Entry, pids []int32, f guardFields) bool {
+	auth := guard.Authorize(target.Action, pids, f.ConfirmToken, f.DryRun)
+	switch {
+	case f.DryRun:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"dry_run": true, "protection": auth})
+	case auth.Denied():
+		target.Result = audit.ResultDenied
+		recordHTTP(alog, r, target)
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "process is protected", "protection": auth})
+	case auth.NeedsConfirmation():
+		target.Result = audit.ResultConfirmationRequired
+		recordHTTP(alog, r, target)
+		w.WriteHeader(http.StatusPreconditionRequired)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "confirmation required", "protection": auth})
+	default:
+		return true
+	}
+	return false
+}
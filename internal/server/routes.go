@@ -2,68 +2,370 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
 	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/rules"
+	"github.com/GalitskyKK/nekkus-eye/internal/settings"
+	"github.com/GalitskyKK/nekkus-eye/internal/snapshots"
+	"github.com/GalitskyKK/nekkus-eye/internal/store"
+	"github.com/GalitskyKK/nekkus-eye/internal/watchlist"
 )
 
-func setCORS(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// allowedOrigins — чужие origin, которым можно обращаться к API из браузера (UI Hub);
+// страницы самого Eye разрешены всегда. См. AllowOrigins.
+var allowedOrigins []string
+
+// AllowOrigins разрешает браузерные запросы к API со страниц origins (например, "http://localhost:5173").
+// Вызывается до RegisterRoutes.
+func AllowOrigins(origins ...string) {
+	for _, o := range origins {
+		if o = strings.TrimSuffix(strings.TrimSpace(o), "/"); o != "" {
+			allowedOrigins = append(allowedOrigins, o)
+		}
+	}
+}
+
+// originAllowed — запрос пришёл со страницы самого Eye (тот же хост и порт, и хост — loopback)
+// или из allowedOrigins. Без проверки loopback страница evil.example, перепривязанная через
+// DNS на 127.0.0.1, присылала бы совпадающие Origin и Host (DNS rebinding).
+func originAllowed(r *http.Request, origin string) bool {
+	if u, err := url.Parse(origin); err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host) && loopbackHost(r.Host) {
+		return true
+	}
+	return slices.Contains(allowedOrigins, origin)
+}
+
+// loopbackHost сообщает, что host (с портом или без) — localhost, 127.0.0.0/8 или ::1.
+func loopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// setCORS открывает ответ только своим страницам. Заголовок "*" от nekkus-core здесь
+// заменяется: иначе любая страница в браузере читала бы процессы, окружение и токены подтверждения.
+func setCORS(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	if origin := r.Header.Get("Origin"); origin != "" && originAllowed(r, origin) {
+		h.Set("Access-Control-Allow-Origin", origin)
+	} else {
+		h.Del("Access-Control-Allow-Origin")
+	}
+	h.Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+	h.Set("Access-Control-Allow-Headers", "Content-Type")
+}
+
+// writeAllowed проверяет изменяющий запрос (POST, DELETE): чужой Origin — 403, тело не JSON — 415.
+// Без этого страница из браузера могла бы отправить text/plain POST без preflight. Клиенты
+// не из браузера (Hub, curl) Origin не присылают. Если запрос отклонён, ответ уже записан.
+func writeAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if origin := r.Header.Get("Origin"); origin != "" && !originAllowed(r, origin) {
+		writeError(w, http.StatusForbidden, "origin not allowed")
+		return false
+	}
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "content type must be application/json")
+		return false
+	}
+	return true
+}
+
+// writeError отвечает JSON {"error": msg} с кодом code.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeStoreError переводит ошибки списка наблюдения, правил и снимков в HTTP-коды.
+// Правилу с confirmed нужен токен — 428 с protection, как у действий над процессами (см. authorize).
+func writeStoreError(w http.ResponseWriter, err error) {
+	var confirm *rules.ConfirmationError
+	switch {
+	case errors.As(err, &confirm):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionRequired)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "protection": confirm.Authorization})
+	case errors.Is(err, store.ErrNotFound), errors.Is(err, monitor.ErrProcessNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, store.ErrInvalid):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, store.ErrExists):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// filesErrorStatus — HTTP-статус ошибки ListOpenFiles и FindFileHolders.
+func filesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, monitor.ErrProcessNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, monitor.ErrInvalidPath):
+		return http.StatusBadRequest
+	case errors.Is(err, fs.ErrPermission):
+		return http.StatusForbidden
+	case errors.Is(err, errors.ErrUnsupported):
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// pathPID разбирает {pid} из пути запроса.
+func pathPID(r *http.Request) (int32, bool) {
+	pid, err := strconv.ParseInt(r.PathValue("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return int32(pid), true
+}
+
+// queryFlag — булев параметр запроса: "1" или "true".
+func queryFlag(r *http.Request, name string) bool {
+	v := r.URL.Query().Get(name)
+	return v == "1" || v == "true"
+}
+
+// parseProcessQuery читает параметры GET /api/processes.
+func parseProcessQuery(r *http.Request) (monitor.ProcessQuery, error) {
+	v := r.URL.Query()
+	q := monitor.ProcessQuery{
+		Sort:        v.Get("sort"),
+		Order:       v.Get("order"),
+		Query:       v.Get("q"),
+		Name:        v.Get("name"),
+		Cmdline:     v.Get("cmdline"),
+		User:        v.Get("user"),
+		Cursor:      v.Get("cursor"),
+		WithMetrics: queryFlag(r, "with_metrics"),
+		Group:       v.Get("group"),
+		WithMembers: queryFlag(r, "members"),
+	}
+	if s := v.Get("status"); s != "" {
+		q.Status = strings.Split(s, ",")
+	}
+	var err error
+	for name, dst := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		if s := v.Get(name); s != "" {
+			if *dst, err = strconv.Atoi(s); err != nil || *dst < 0 {
+				return q, fmt.Errorf("invalid %s", name)
+			}
+		}
+	}
+	if s := v.Get("min_cpu"); s != "" {
+		if q.MinCPU, err = strconv.ParseFloat(s, 64); err != nil {
+			return q, errors.New("invalid min_cpu")
+		}
+	}
+	if s := v.Get("min_rss_mb"); s != "" {
+		if q.MinRSSMB, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return q, errors.New("invalid min_rss_mb")
+		}
+	}
+	return q, nil
 }
 
 // RegisterRoutes регистрирует API маршруты для nekkus-eye.
-func RegisterRoutes(srv *coreserver.Server, collector *monitor.Collector) {
-	srv.Mux.HandleFunc("GET /api/stats", func(w http.ResponseWriter, _ *http.Request) {
-		setCORS(w)
+func RegisterRoutes(srv *coreserver.Server, collector *monitor.Collector, store *settings.Store, wl *watchlist.Watchlist,
+	guard *monitor.ProcessGuard, alog *audit.Log, rl *rules.Engine, snaps *snapshots.Store) {
+	srv.Mux.HandleFunc("GET /api/stats", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
 		w.Header().Set("Content-Type", "application/json")
 		stats := collector.Get()
-		topProcs, _ := monitor.ListTopProcessesByCPU(5)
+		topProcs := collector.ListTopProcessesByCPU(5)
+		topGPU := collector.ListTopProcessesByGPU(5, "gpu", "")
+		topIO := collector.ListTopProcessesByIO(5)
 		var resp map[string]interface{}
 		if b, err := json.Marshal(stats); err == nil && json.Unmarshal(b, &resp) == nil {
 			resp["top_processes"] = topProcs
+			if len(topGPU) > 0 {
+				resp["top_gpu_processes"] = topGPU
+			}
+			if topIO == nil {
+				topIO = []monitor.ProcessInfo{}
+			}
+			resp["top_io_processes"] = topIO
 		} else {
 			resp = map[string]interface{}{"timestamp": stats.Timestamp, "top_processes": topProcs}
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	})
 
-	srv.Mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, _ *http.Request) {
-		setCORS(w)
+	srv.Mux.HandleFunc("GET /api/gpus", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		gpus := collector.Get().GPUs
+		if gpus == nil {
+			gpus = []monitor.GPUInfo{}
+		}
+		_ = json.NewEncoder(w).Encode(gpus)
+	})
+
+	srv.Mux.HandleFunc("GET /api/gpus/processes", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		procs := collector.GPUProcesses()
+		if procs == nil {
+			procs = []monitor.GPUProcess{}
+		}
+		_ = json.NewEncoder(w).Encode(procs)
+	})
+
+	srv.Mux.HandleFunc("GET /api/gpus/{index}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		index, err := strconv.Atoi(r.PathValue("index"))
+		if err != nil {
+			http.Error(w, `{"error":"invalid index"}`, http.StatusBadRequest)
+			return
+		}
+		for _, g := range collector.Get().GPUs {
+			if g.Index == index {
+				_ = json.NewEncoder(w).Encode(g)
+				return
+			}
+		}
+		http.Error(w, `{"error":"gpu not found"}`, http.StatusNotFound)
+	})
+
+	srv.Mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 	})
 
+	// GET /api/processes — страница процессов: фильтры, сортировка и пагинация на сервере.
+	// sort=pid|name|cpu|rss|pss|uss|swap|connections|start_time|gpu|gpu_memory|disk_read|disk_write|disk_io,
+	// order=asc|desc, q, name, cmdline (regex), user, status (через запятую), min_cpu, min_rss_mb,
+	// limit, offset или cursor (next_cursor прошлой страницы), with_metrics=1 (соединения и PSS/USS/swap).
+	// group=app|pgid|session — вместо процессов страница групп с суммами (sort=count — по числу
+	// процессов), members=1 — с процессами каждой группы.
 	srv.Mux.HandleFunc("GET /api/processes", func(w http.ResponseWriter, r *http.Request) {
-		setCORS(w)
+		setCORS(w, r)
 		w.Header().Set("Content-Type", "application/json")
-		limit := 200
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if n, err := strconv.Atoi(l); err == nil && n > 0 {
-				limit = n
+		q, err := parseProcessQuery(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var page interface{}
+		if q.Group != "" {
+			page, err = collector.ListProcessGroups(q)
+		} else {
+			page, err = collector.ListProcesses(q)
+		}
+		if errors.Is(err, monitor.ErrInvalidQuery) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	// GET /api/processes/tree[?pid=N] — иерархия процессов с суммами CPU/RSS по поддеревьям.
+	srv.Mux.HandleFunc("GET /api/processes/tree", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		var root int32
+		if v := r.URL.Query().Get("pid"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil || n <= 0 {
+				writeError(w, http.StatusBadRequest, "invalid pid")
+				return
+			}
+			root = int32(n)
+		}
+		tree, err := collector.BuildProcessTree(root)
+		if errors.Is(err, monitor.ErrProcessNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(tree)
+	})
+
+	// GET /api/processes/stuck[?min_sec=N] — зомби, процессы в непрерываемом сне (D) и остановленные:
+	// сколько они в этом состоянии, wchan, родители зомби. min_sec — не показывать более короткие.
+	srv.Mux.HandleFunc("GET /api/processes/stuck", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		var minAge time.Duration
+		if v := r.URL.Query().Get("min_sec"); v != "" {
+			sec, err := strconv.ParseFloat(v, 64)
+			if err != nil || sec < 0 {
+				writeError(w, http.StatusBadRequest, "invalid min_sec")
+				return
 			}
+			minAge = time.Duration(sec * float64(time.Second))
+		}
+		_ = json.NewEncoder(w).Encode(collector.StuckProcesses(minAge))
+	})
+
+	srv.Mux.HandleFunc("GET /api/processes/{pid}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		pid, ok := pathPID(r)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid pid")
+			return
+		}
+		// Окружение — только по явному запросу (?env=1), секреты скрываются по secret_patterns.
+		withEnv := queryFlag(r, "env")
+		detail, err := monitor.GetProcessDetail(pid, monitor.DetailOptions{
+			WithEnv:        withEnv,
+			SecretPatterns: store.Get().SecretPatterns,
+		})
+		if errors.Is(err, monitor.ErrProcessNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
 		}
-		q := r.URL.Query().Get("q")
-		withMetrics := r.URL.Query().Get("with_metrics") == "1" || r.URL.Query().Get("with_metrics") == "true"
-		list, err := monitor.ListProcesses(limit, q, withMetrics)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		_ = json.NewEncoder(w).Encode(list)
+		_ = json.NewEncoder(w).Encode(detail)
 	})
 
+	// POST /api/processes/kill — {"pid": 123}; dry_run и confirm_token — см. guardFields.
 	srv.Mux.HandleFunc("POST /api/processes/kill", func(w http.ResponseWriter, r *http.Request) {
-		setCORS(w)
+		setCORS(w, r)
 		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
 		if r.Method == "OPTIONS" {
 			return
 		}
 		var body struct {
 			PID int32 `json:"pid"`
+			guardFields
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
@@ -73,10 +375,173 @@ func RegisterRoutes(srv *coreserver.Server, collector *monitor.Collector) {
 			http.Error(w, `{"error":"invalid pid"}`, http.StatusBadRequest)
 			return
 		}
-		if err := monitor.KillProcess(body.PID); err != nil {
+		target := audit.Target("kill", body.PID)
+		if !authorize(w, r, guard, alog, target, []int32{body.PID}, body.guardFields) {
+			return
+		}
+		err := monitor.KillProcess(body.PID)
+		recordHTTP(alog, r, withError(target, err))
+		if err != nil {
 			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
 			return
 		}
 		_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 	})
+
+	// GET /api/processes/{pid}/connections[?resolve=1] — сокеты процесса.
+	// resolve добавляет remote_host из кэша обратного DNS; новые адреса резолвятся в фоне.
+	srv.Mux.HandleFunc("GET /api/processes/{pid}/connections", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		pid, ok := pathPID(r)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid pid")
+			return
+		}
+		conns, err := collector.ProcessConnections(pid, queryFlag(r, "resolve"))
+		if errors.Is(err, monitor.ErrProcessNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(conns)
+	})
+
+	// GET /api/processes/{pid}/threads — потоки процесса с CPU% за интервал с прошлого запроса
+	// (первый запрос по процессу ждёт полсекунды). Только Linux.
+	srv.Mux.HandleFunc("GET /api/processes/{pid}/threads", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		pid, ok := pathPID(r)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid pid")
+			return
+		}
+		threads, err := collector.ProcessThreads(pid)
+		switch {
+		case errors.Is(err, monitor.ErrProcessNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		case errors.Is(err, errors.ErrUnsupported):
+			writeError(w, http.StatusNotImplemented, "threads are not supported on this platform")
+			return
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(threads)
+	})
+
+	// GET /api/processes/{pid}/files — открытые дескрипторы процесса: путь, тип и режим. Только Linux.
+	srv.Mux.HandleFunc("GET /api/processes/{pid}/files", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		pid, ok := pathPID(r)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid pid")
+			return
+		}
+		files, err := monitor.ListOpenFiles(pid)
+		if err != nil {
+			writeError(w, filesErrorStatus(err), err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(files)
+	})
+
+	// GET /api/files/holders?path=/mnt/usb — процессы, которые держат файл или что-то
+	// внутри каталога (дескриптор, cwd, root, exe, mmap). Только Linux.
+	srv.Mux.HandleFunc("GET /api/files/holders", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			writeError(w, http.StatusBadRequest, "path is required")
+			return
+		}
+		holders, err := monitor.FindFileHolders(r.Context(), path)
+		if err != nil {
+			writeError(w, filesErrorStatus(err), err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(holders)
+	})
+
+	// GET /api/connections/hosts[?resolve=1&exclude_loopback=1] — удалённые адреса всех процессов.
+	srv.Mux.HandleFunc("GET /api/connections/hosts", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		hosts, err := collector.RemoteHosts(queryFlag(r, "resolve"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if queryFlag(r, "exclude_loopback") {
+			filtered := hosts[:0]
+			for _, h := range hosts {
+				if !h.Loopback {
+					filtered = append(filtered, h)
+				}
+			}
+			hosts = filtered
+		}
+		_ = json.NewEncoder(w).Encode(hosts)
+	})
+
+	// POST /api/processes/kill-tree — завершить процесс вместе с потомками (дети первыми).
+	// Защита проверяет каждый процесс дерева; один confirm_token подтверждает всё дерево.
+	srv.Mux.HandleFunc("POST /api/processes/kill-tree", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		var body struct {
+			PID int32 `json:"pid"`
+			guardFields
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		if body.PID <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid pid")
+			return
+		}
+		pids, err := monitor.ProcessTreePIDs(body.PID)
+		if errors.Is(err, monitor.ErrProcessNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		target := audit.Target("kill-tree", body.PID)
+		if !authorize(w, r, guard, alog, target, pids, body.guardFields) {
+			return
+		}
+		res, err := monitor.KillProcessList(body.PID, pids)
+		target = withError(target, err)
+		target.Details = map[string]string{"killed": strconv.Itoa(len(res.Killed)), "failed": strconv.Itoa(len(res.Failed))}
+		recordHTTP(alog, r, target)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": err.Error(), "result": res})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": res})
+	})
+
+	registerSignalRoutes(srv, store, guard, alog)
+	registerPriorityRoutes(srv, guard, alog)
+	registerEventRoutes(srv, collector)
+	registerWatchlistRoutes(srv, wl, alog)
+	registerRuleRoutes(srv, rl, alog)
+	registerSnapshotRoutes(srv, snaps, alog)
+	registerSettingsRoutes(srv, store, alog)
+	registerAuditRoutes(srv, alog)
 }
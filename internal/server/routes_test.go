@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowedOrigins = []string{"http://localhost:5173"}
+	defer func() { allowedOrigins = nil }()
+	tests := []struct {
+		host, origin string
+		want         bool
+	}{
+		{"localhost:9002", "http://localhost:9002", true},
+		{"127.0.0.1:9002", "http://127.0.0.1:9002", true},
+		{"[::1]:9002", "http://[::1]:9002", true},
+		{"127.0.0.1:9002", "http://localhost:5173", true},  // --allow-origin
+		{"127.0.0.1:9002", "http://localhost:9003", false}, // другой порт
+		{"127.0.0.1:9002", "https://evil.example", false},
+		{"evil.example:9002", "http://evil.example:9002", false}, // DNS rebinding
+		{"192.168.1.5:9002", "http://192.168.1.5:9002", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/stats", nil)
+		r.Host = tt.host
+		if got := originAllowed(r, tt.origin); got != tt.want {
+			t.Errorf("originAllowed(host %s, origin %s) = %v, want %v", tt.host, tt.origin, got, tt.want)
+		}
+	}
+}
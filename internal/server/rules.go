@@ -0,0 +1,202 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/rules"
+)
+
+// ruleFiringMessage — сообщение WebSocket (/ws) о срабатывании правила.
+type ruleFiringMessage struct {
+	Type   string       `json:"type"` // "rule_firing"
+	Firing rules.Firing `json:"firing"`
+}
+
+func registerRuleRoutes(srv *coreserver.Server, rl *rules.Engine, alog *audit.Log) {
+	srv.Mux.HandleFunc("GET /api/rules", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(rl.List())
+	})
+
+	// POST /api/rules — добавить правило:
+	// {"name": "...", "enabled": true, "match": {"name": "java"}, "conditions": [{"metric": "rss", "op": ">", "value": 4096}],
+	//  "for_sec": 60, "action": "notify|renice|suspend|terminate", "nice": 10, "grace_ms": 5000, "cooldown_sec": 300, "dry_run": false}.
+	// С "confirmed": true для renice, suspend и terminate — 428 с токеном; повторите запрос с "confirm_token".
+	srv.Mux.HandleFunc("POST /api/rules", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		var body struct {
+			rules.Rule
+			ConfirmToken string `json:"confirm_token,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		added, err := rl.Add(body.Rule, body.ConfirmToken)
+		if err != nil {
+			added = body.Rule
+		}
+		recordHTTP(alog, r, ruleAudit("rules.add", added, err))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(added)
+	})
+
+	// POST /api/rules/test — проверить правило (тело как у POST /api/rules) по текущим процессам,
+	// не сохраняя: подходящие процессы, их метрики и выполняются ли условия сейчас.
+	srv.Mux.HandleFunc("POST /api/rules/test", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		var rule rules.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		candidates, err := rl.Test(rule)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"candidates": candidates})
+	})
+
+	// GET /api/rules/firings — срабатывания правил, от старых к новым.
+	// rule=<id>, after=<id> (для опроса), since=<unix>, limit (по умолчанию 500 последних).
+	srv.Mux.HandleFunc("GET /api/rules/firings", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		v := r.URL.Query()
+		f := rules.FiringFilter{RuleID: v.Get("rule"), Limit: 500}
+		if s := v.Get("after"); s != "" {
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid after")
+				return
+			}
+			f.AfterID = id
+		}
+		if s := v.Get("since"); s != "" {
+			sec, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid since")
+				return
+			}
+			f.Since = time.Unix(sec, 0)
+		}
+		if s := v.Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			f.Limit = n
+		}
+		_ = json.NewEncoder(w).Encode(rl.Firings(f))
+	})
+
+	srv.Mux.HandleFunc("GET /api/rules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		st, err := rl.Get(r.PathValue("id"))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(st)
+	})
+
+	// POST /api/rules/{id} — частичное обновление: поля, которых нет в теле, не меняются;
+	// confirm_token — как у POST /api/rules.
+	srv.Mux.HandleFunc("POST /api/rules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		raw, err := io.ReadAll(r.Body)
+		var body struct {
+			ConfirmToken string `json:"confirm_token"`
+		}
+		if err != nil || json.Unmarshal(raw, &body) != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		updated, err := rl.Update(r.PathValue("id"), func(rule *rules.Rule) error {
+			if err := json.Unmarshal(raw, rule); err != nil {
+				return fmt.Errorf("%w: invalid json", rules.ErrInvalid)
+			}
+			return nil
+		}, body.ConfirmToken)
+		if err == nil || !errors.Is(err, rules.ErrNotFound) {
+			recordHTTP(alog, r, ruleAudit("rules.update", updated, err))
+		}
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(updated)
+	})
+
+	srv.Mux.HandleFunc("DELETE /api/rules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		deleted, err := rl.Delete(r.PathValue("id"))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		recordHTTP(alog, r, ruleAudit("rules.delete", deleted, nil))
+		_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	})
+
+	// Пуш срабатываний всем клиентам WebSocket — без командной строки, как и события
+	// процессов (см. registerEventRoutes); полностью — GET /api/rules/firings.
+	go func() {
+		firings, _ := rl.Subscribe()
+		for f := range firings {
+			f.Cmdline = ""
+			srv.Broadcast(ruleFiringMessage{Type: "rule_firing", Firing: f})
+		}
+	}()
+}
+
+// ruleAudit — запись аудита об изменении правил.
+func ruleAudit(action string, r rules.Rule, err error) audit.Entry {
+	entry := withError(audit.Entry{Action: action}, err)
+	var confirm *rules.ConfirmationError
+	if errors.As(err, &confirm) {
+		entry.Result, entry.Error = audit.ResultConfirmationRequired, ""
+	}
+	if r.Action != "" || r.ID != "" {
+		entry.Details = map[string]string{"action": r.Action, "match": r.Match.String()}
+		if r.ID != "" {
+			entry.Details["id"] = r.ID
+			entry.Details["enabled"] = strconv.FormatBool(r.Enabled)
+			entry.Details["dry_run"] = strconv.FormatBool(r.DryRun)
+			entry.Details["confirmed"] = strconv.FormatBool(r.Confirmed)
+		}
+	}
+	return entry
+}
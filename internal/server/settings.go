@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/settings"
+)
+
+func registerSettingsRoutes(srv *coreserver.Server, store *settings.Store, alog *audit.Log) {
+	srv.Mux.HandleFunc("GET /api/settings", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(store.Get())
+	})
+
+	// POST /api/settings — частичное обновление: поля, которых нет в теле, не меняются.
+	// Принимается только JSON со страниц Eye: иначе чужая страница могла бы очистить secret_patterns
+	// или protected_names и затем прочитать окружение процессов.
+	srv.Mux.HandleFunc("POST /api/settings", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		// Тело читаем до Update: медленный клиент не должен держать блокировку настроек,
+		// которые читают защита процессов и terminate.
+		raw, err := io.ReadAll(r.Body)
+		if err != nil || !json.Valid(raw) {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		before := store.Get()
+		updated, err := store.Update(func(s *settings.Settings) error {
+			if err := json.Unmarshal(raw, s); err != nil {
+				return errors.New("invalid json")
+			}
+			return nil
+		})
+		entry := withError(audit.Entry{Action: "settings"}, err)
+		if err == nil {
+			entry.Details = changedFields(before, updated)
+		}
+		recordHTTP(alog, r, entry)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(updated)
+	})
+}
+
+// changedFields — поля настроек (по JSON-именам), которые отличаются в after, с новыми значениями.
+func changedFields(before, after settings.Settings) map[string]string {
+	var a, b map[string]json.RawMessage
+	ab, _ := json.Marshal(before)
+	bb, _ := json.Marshal(after)
+	_ = json.Unmarshal(ab, &a)
+	_ = json.Unmarshal(bb, &b)
+	out := map[string]string{}
+	for k, v := range b {
+		if string(a[k]) != string(v) {
+			out[k] = string(v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/settings"
+)
+
+// signalRequest — тело POST /api/processes/{terminate,suspend,resume,signal}.
+type signalRequest struct {
+	PID     int32  `json:"pid"`
+	Signal  string `json:"signal,omitempty"`
+	GraceMs int    `json:"grace_ms,omitempty"`
+	// Inherit — для restart: если каталог или окружение процесса не прочитать, взять их у Eye.
+	Inherit bool `json:"inherit,omitempty"`
+	guardFields
+}
+
+func decodeSignalRequest(w http.ResponseWriter, r *http.Request) (signalRequest, bool) {
+	var body signalRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return body, false
+	}
+	if body.PID <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid pid")
+		return body, false
+	}
+	return body, true
+}
+
+// signalStatus — HTTP-код для исхода операции над процессом.
+func signalStatus(res monitor.SignalResult) int {
+	if res.OK() {
+		return http.StatusOK
+	}
+	return outcomeStatus(res.Outcome)
+}
+
+// outcomeStatus — HTTP-код для неуспешного исхода (monitor.Outcome*).
+func outcomeStatus(outcome string) int {
+	switch outcome {
+	case monitor.OutcomeNotFound:
+		return http.StatusNotFound
+	case monitor.OutcomePermissionDenied:
+		return http.StatusForbidden
+	case monitor.OutcomeNotSupported:
+		return http.StatusNotImplemented
+	case monitor.OutcomeInvalidSignal, monitor.OutcomeInvalidArgument:
+		return http.StatusBadRequest
+	case monitor.OutcomeStillRunning:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func writeSignalResult(w http.ResponseWriter, res monitor.SignalResult) {
+	w.WriteHeader(signalStatus(res))
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func registerSignalRoutes(srv *coreserver.Server, store *settings.Store, guard *monitor.ProcessGuard, alog *audit.Log) {
+	// POST /api/processes/terminate — SIGTERM, ожидание grace_ms (по умолчанию из настроек), затем SIGKILL.
+	// С ?stream=1 ответ — NDJSON: строки прогресса, последней — итог.
+	// terminate, suspend и signal проходят защиту процессов: dry_run и confirm_token — см. guardFields.
+	srv.Mux.HandleFunc("POST /api/processes/terminate", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		body, ok := decodeSignalRequest(w, r)
+		if !ok {
+			return
+		}
+		target := audit.Target("terminate", body.PID)
+		if !authorize(w, r, guard, alog, target, []int32{body.PID}, body.guardFields) {
+			return
+		}
+		grace := store.Get().TerminateGrace()
+		if body.GraceMs > 0 {
+			grace = time.Duration(body.GraceMs) * time.Millisecond
+		}
+
+		flusher, canFlush := w.(http.Flusher)
+		if !queryFlag(r, "stream") || !canFlush {
+			res := monitor.TerminateProcess(r.Context(), body.PID, grace, nil)
+			recordHTTP(alog, r, withSignalResult(target, res))
+			writeSignalResult(w, res)
+			return
+		}
+		// В потоковом режиме код ответа уже отправлен, исход — в поле outcome последней строки.
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		res := monitor.TerminateProcess(r.Context(), body.PID, grace, func(p monitor.SignalProgress) {
+			_ = enc.Encode(p)
+			flusher.Flush()
+		})
+		res.Progress = nil
+		recordHTTP(alog, r, withSignalResult(target, res))
+		_ = enc.Encode(res)
+	})
+
+	// POST /api/processes/restart — перезапуск с теми же exe, argv, cwd и окружением:
+	// terminate с grace_ms, затем запуск отдельно от Eye. Возвращает new_pid. Если каталог или
+	// окружение процесса не прочитать — 501; с "inherit": true вместо них берутся каталог и окружение Eye.
+	srv.Mux.HandleFunc("POST /api/processes/restart", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		body, ok := decodeSignalRequest(w, r)
+		if !ok {
+			return
+		}
+		target := audit.Target("restart", body.PID)
+		if !authorize(w, r, guard, alog, target, []int32{body.PID}, body.guardFields) {
+			return
+		}
+		grace := store.Get().TerminateGrace()
+		if body.GraceMs > 0 {
+			grace = time.Duration(body.GraceMs) * time.Millisecond
+		}
+		res := monitor.RestartProcess(r.Context(), body.PID, grace, body.Inherit)
+		target.Result, target.Error = res.Outcome, res.Error
+		if res.NewPID > 0 {
+			target.Details = map[string]string{"new_pid": strconv.Itoa(int(res.NewPID))}
+		}
+		recordHTTP(alog, r, target)
+		if !res.OK() {
+			w.WriteHeader(outcomeStatus(res.Outcome))
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+
+	srv.Mux.HandleFunc("POST /api/processes/suspend", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		body, ok := decodeSignalRequest(w, r)
+		if !ok {
+			return
+		}
+		target := audit.Target("suspend", body.PID)
+		if authorize(w, r, guard, alog, target, []int32{body.PID}, body.guardFields) {
+			res := monitor.SuspendProcess(body.PID)
+			recordHTTP(alog, r, withSignalResult(target, res))
+			writeSignalResult(w, res)
+		}
+	})
+
+	srv.Mux.HandleFunc("POST /api/processes/resume", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		if body, ok := decodeSignalRequest(w, r); ok {
+			target := audit.Target("resume", body.PID)
+			res := monitor.ResumeProcess(body.PID)
+			recordHTTP(alog, r, withSignalResult(target, res))
+			writeSignalResult(w, res)
+		}
+	})
+
+	// POST /api/processes/signal — произвольный сигнал: {"pid": 123, "signal": "HUP"}.
+	srv.Mux.HandleFunc("POST /api/processes/signal", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		body, ok := decodeSignalRequest(w, r)
+		if !ok {
+			return
+		}
+		if body.Signal == "" {
+			writeError(w, http.StatusBadRequest, "signal is required")
+			return
+		}
+		target := audit.Target("signal", body.PID)
+		target.Details = map[string]string{"signal": body.Signal}
+		if !authorize(w, r, guard, alog, target, []int32{body.PID}, body.guardFields) {
+			return
+		}
+		res := monitor.SignalProcess(body.PID, body.Signal)
+		recordHTTP(alog, r, withSignalResult(target, res))
+		writeSignalResult(w, res)
+	})
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/snapshots"
+)
+
+func registerSnapshotRoutes(srv *coreserver.Server, st *snapshots.Store, alog *audit.Log) {
+	// GET /api/snapshots — сохранённые снимки таблицы процессов, от новых к старым.
+	srv.Mux.HandleFunc("GET /api/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(st.List())
+	})
+
+	// POST /api/snapshots — снять таблицу процессов: {"name": "before-upgrade"}; без имени — по времени.
+	srv.Mux.HandleFunc("POST /api/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		var body struct {
+			Name string `json:"name"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid json")
+				return
+			}
+		}
+		info, err := st.Capture(body.Name)
+		if info.Name == "" {
+			info.Name = body.Name
+		}
+		recordHTTP(alog, r, snapshotAudit("snapshots.capture", info, err))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(info)
+	})
+
+	// GET /api/snapshots/{name} — снимок целиком, с процессами.
+	srv.Mux.HandleFunc("GET /api/snapshots/{name}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		snap, err := st.Get(r.PathValue("name"))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(snap)
+	})
+
+	srv.Mux.HandleFunc("DELETE /api/snapshots/{name}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		info, err := st.Delete(r.PathValue("name"))
+		if err == nil {
+			recordHTTP(alog, r, snapshotAudit("snapshots.delete", info, nil))
+		}
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	})
+
+	// GET /api/snapshots/{name}/diff[?to=<name>|now&min_cpu=5&min_rss_mb=10] — что появилось,
+	// исчезло и заметно изменилось по памяти или CPU между снимком name и to (по умолчанию now —
+	// текущая таблица процессов).
+	srv.Mux.HandleFunc("GET /api/snapshots/{name}/diff", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		v := r.URL.Query()
+		to := v.Get("to")
+		if to == "" {
+			to = snapshots.Now
+		}
+		var opt snapshots.DiffOptions
+		if s := v.Get("min_cpu"); s != "" {
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid min_cpu")
+				return
+			}
+			opt.MinCPU = n
+		}
+		if s := v.Get("min_rss_mb"); s != "" {
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid min_rss_mb")
+				return
+			}
+			opt.MinRSSMB = n
+		}
+		diff, err := st.Diff(r.PathValue("name"), to, opt)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(diff)
+	})
+}
+
+// snapshotAudit — запись аудита о снимке таблицы процессов.
+func snapshotAudit(action string, info snapshots.Info, err error) audit.Entry {
+	entry := withError(audit.Entry{Action: action}, err)
+	if info.Name != "" {
+		entry.Details = map[string]string{"name": info.Name}
+		if info.ProcessCount > 0 {
+			entry.Details["processes"] = strconv.Itoa(info.ProcessCount)
+		}
+	}
+	return entry
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	coreserver "github.com/GalitskyKK/nekkus-core/pkg/server"
+	"github.com/GalitskyKK/nekkus-eye/internal/audit"
+	"github.com/GalitskyKK/nekkus-eye/internal/watchlist"
+)
+
+func registerWatchlistRoutes(srv *coreserver.Server, wl *watchlist.Watchlist, alog *audit.Log) {
+	srv.Mux.HandleFunc("GET /api/watchlist", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(wl.List())
+	})
+
+	// POST /api/watchlist — добавить запись: {"kind": "pid|exe|name|cmdline", "pattern": "...", "label": "..."}.
+	srv.Mux.HandleFunc("POST /api/watchlist", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		var e watchlist.Entry
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		added, err := wl.Add(e)
+		if err != nil {
+			added = e
+		}
+		recordHTTP(alog, r, watchAudit("watchlist.add", added, err))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(added)
+	})
+
+	// GET /api/watchlist/{id} — запись с последним замером (данные виджета Hub).
+	srv.Mux.HandleFunc("GET /api/watchlist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		st, err := wl.Get(r.PathValue("id"))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(st)
+	})
+
+	// POST /api/watchlist/{id} — частичное обновление: поля, которых нет в теле, не меняются.
+	srv.Mux.HandleFunc("POST /api/watchlist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		updated, err := wl.Update(r.PathValue("id"), func(e *watchlist.Entry) error {
+			if err := json.NewDecoder(r.Body).Decode(e); err != nil {
+				return fmt.Errorf("%w: invalid json", watchlist.ErrInvalid)
+			}
+			return nil
+		})
+		if err == nil || !errors.Is(err, watchlist.ErrNotFound) {
+			recordHTTP(alog, r, watchAudit("watchlist.update", updated, err))
+		}
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(updated)
+	})
+
+	srv.Mux.HandleFunc("DELETE /api/watchlist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		if !writeAllowed(w, r) {
+			return
+		}
+		id := r.PathValue("id")
+		entry, _ := wl.Get(id)
+		err := wl.Delete(id)
+		if err == nil {
+			recordHTTP(alog, r, watchAudit("watchlist.delete", entry.Entry, nil))
+		}
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	})
+
+	// GET /api/watchlist/{id}/history[?since=<unix>&points=N] — замеры записи (раз в 5 с, за сутки).
+	srv.Mux.HandleFunc("GET /api/watchlist/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w, r)
+		w.Header().Set("Content-Type", "application/json")
+		v := r.URL.Query()
+		var since time.Time
+		if s := v.Get("since"); s != "" {
+			sec, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid since")
+				return
+			}
+			since = time.Unix(sec, 0)
+		}
+		points := 0
+		if s := v.Get("points"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid points")
+				return
+			}
+			points = n
+		}
+		samples, err := wl.History(r.PathValue("id"), since, points)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(samples)
+	})
+}
+
+// watchAudit — запись аудита об изменении списка наблюдения.
+func watchAudit(action string, e watchlist.Entry, err error) audit.Entry {
+	entry := withError(audit.Entry{Action: action}, err)
+	if e.Kind != "" || e.Pattern != "" {
+		entry.Details = map[string]string{"kind": e.Kind, "pattern": e.Pattern}
+		if e.ID != "" {
+			entry.Details["id"] = e.ID
+		}
+	}
+	return entry
+}
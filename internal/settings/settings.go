@@ -0,0 +1,139 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/store"
+)
+
+const fileName = "settings.json"
+
+// Settings — пользовательские настройки Eye, хранятся в data-dir/settings.json.
+type Settings struct {
+	// SecretPatterns — регулярные выражения для имён переменных окружения,
+	// значения которых скрываются в деталях процесса.
+	SecretPatterns []string `json:"secret_patterns"`
+	// TerminateGraceMs — сколько ждать после SIGTERM перед SIGKILL.
+	TerminateGraceMs int `json:"terminate_grace_ms"`
+	// ProtectedNames — имена процессов, которые можно завершить или приостановить
+	// только с подтверждением (без учёта регистра).
+	ProtectedNames []string `json:"protected_names"`
+}
+
+// Defaults возвращает настройки по умолчанию.
+func Defaults() Settings {
+	return Settings{
+		SecretPatterns: []string{
+			`(?i)pass(word|wd)?`,
+			`(?i)secret`,
+			`(?i)token`,
+			`(?i)(api|access|private)_?key`,
+			`(?i)auth`,
+			`(?i)credential`,
+			`(?i)cookie|session`,
+		},
+		TerminateGraceMs: 5000,
+		ProtectedNames:   []string{},
+	}
+}
+
+// Validate проверяет, что настройки можно применить.
+func (s Settings) Validate() error {
+	for _, p := range s.SecretPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("secret_patterns: %w", err)
+		}
+	}
+	if s.TerminateGraceMs <= 0 || time.Duration(s.TerminateGraceMs)*time.Millisecond > monitor.MaxTerminateGrace {
+		return fmt.Errorf("terminate_grace_ms: must be in 1..%d", monitor.MaxTerminateGrace.Milliseconds())
+	}
+	for _, n := range s.ProtectedNames {
+		if strings.TrimSpace(n) == "" {
+			return errors.New("protected_names: empty name")
+		}
+	}
+	return nil
+}
+
+// TerminateGrace возвращает grace-период как time.Duration.
+func (s Settings) TerminateGrace() time.Duration {
+	return time.Duration(s.TerminateGraceMs) * time.Millisecond
+}
+
+// clone копирует настройки вместе со срезами, чтобы вызывающий код не менял их в Store.
+func (s Settings) clone() Settings {
+	c := s
+	c.SecretPatterns = append([]string(nil), s.SecretPatterns...)
+	c.ProtectedNames = append([]string{}, s.ProtectedNames...)
+	return c
+}
+
+// Store — потокобезопасное хранилище настроек с сохранением на диск.
+type Store struct {
+	mu   sync.RWMutex
+	path string
+	cur  Settings
+}
+
+// Load читает настройки из dataDir; если файла нет — используются значения по умолчанию.
+// Испорченный или не прошедший Validate файл не применяется даже частично: настройки
+// по умолчанию, а сам файл копируется рядом, чтобы его не затёрло следующее сохранение.
+func Load(dataDir string) (*Store, error) {
+	st := &Store{path: filepath.Join(dataDir, fileName), cur: Defaults()}
+	b, err := os.ReadFile(st.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	// Поля, которых нет в файле, остаются по умолчанию.
+	s := Defaults()
+	if err = json.Unmarshal(b, &s); err == nil {
+		err = s.Validate()
+	}
+	if err != nil {
+		return st, errors.Join(fmt.Errorf("%s: %w", st.path, err), store.Backup(st.path, b))
+	}
+	st.cur = s
+	return st, nil
+}
+
+// Get возвращает копию текущих настроек.
+func (st *Store) Get() Settings {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.cur.clone()
+}
+
+// Update применяет fn к копии настроек, проверяет результат и сохраняет его на диск.
+// Если fn вернула ошибку, настройки не меняются.
+func (st *Store) Update(fn func(*Settings) error) (Settings, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	next := st.cur.clone()
+	if err := fn(&next); err != nil {
+		return st.cur.clone(), err
+	}
+	if err := next.Validate(); err != nil {
+		return st.cur.clone(), err
+	}
+	if err := st.save(next); err != nil {
+		return st.cur.clone(), err
+	}
+	st.cur = next
+	return next, nil
+}
+
+func (st *Store) save(s Settings) error {
+	return store.SaveJSON(st.path, s, 0644)
+}
@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadInvalidFile(t *testing.T) {
+	tests := []struct {
+		name, data string
+	}{
+		{"broken json", `{"terminate_grace_ms": 9000,`},
+		{"out of range", `{"terminate_grace_ms": 0}`},
+		{"bad pattern", `{"terminate_grace_ms": 9000, "secret_patterns": ["("]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, fileName), []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			st, err := Load(dir)
+			if err == nil {
+				t.Fatal("Load: want error")
+			}
+			if got := st.Get(); !reflect.DeepEqual(got, Defaults().clone()) {
+				t.Errorf("settings = %+v, want defaults", got)
+			}
+			baks, _ := filepath.Glob(filepath.Join(dir, fileName+".*.bak"))
+			if len(baks) != 1 {
+				t.Fatalf("backups = %v, want one", baks)
+			}
+			if b, _ := os.ReadFile(baks[0]); string(b) != tt.data {
+				t.Errorf("backup = %q, want %q", b, tt.data)
+			}
+		})
+	}
+}
+
+func TestLoadPartialFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte(`{"terminate_grace_ms": 9000}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Defaults()
+	want.TerminateGraceMs = 9000
+	if got := st.Get(); !reflect.DeepEqual(got, want.clone()) {
+		t.Errorf("settings = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+package snapshots
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Пороги DiffOptions по умолчанию: меньшие изменения считаются шумом.
+const (
+	defaultMinCPU   = 5  // процентных пунктов
+	defaultMinRSSMB = 10 // МБ
+)
+
+// DiffOptions — с какого изменения процесс попадает в Diff.Changed; 0 — порог по умолчанию.
+type DiffOptions struct {
+	MinCPU   float64
+	MinRSSMB uint64
+}
+
+// Change — процесс, который есть в обоих снимках, но заметно изменился.
+type Change struct {
+	PID     int32  `json:"pid"`
+	PrevPID int32  `json:"prev_pid,omitempty"` // если процесс перезапущен
+	Name    string `json:"name"`
+	Cmdline string `json:"cmdline,omitempty"`
+	// Restarted — тот же exe и cmdline, но другой экземпляр процесса.
+	Restarted   bool    `json:"restarted,omitempty"`
+	CPUBefore   float64 `json:"cpu_before"`
+	CPUAfter    float64 `json:"cpu_after"`
+	CPUDelta    float64 `json:"cpu_delta"`
+	RSSBeforeMB uint64  `json:"rss_before_mb"`
+	RSSAfterMB  uint64  `json:"rss_after_mb"`
+	RSSDeltaMB  int64   `json:"rss_delta_mb"`
+}
+
+// Diff — разница между двумя снимками.
+type Diff struct {
+	From        Info      `json:"from"`
+	To          Info      `json:"to"`
+	Appeared    []Process `json:"appeared"`    // по убыванию RSS
+	Disappeared []Process `json:"disappeared"` // по убыванию RSS
+	// Changed — выросшие и уменьшившиеся по памяти или CPU, по убыванию |RSSDeltaMB|;
+	// перезапущенные процессы попадают сюда всегда.
+	Changed   []Change `json:"changed"`
+	Unchanged int      `json:"unchanged"`
+	// RSSDeltaMB — разница суммарного RSS всех процессов.
+	RSSDeltaMB int64 `json:"rss_delta_mb"`
+}
+
+// Diff сравнивает снимки from и to; Now вместо имени — текущая таблица процессов.
+// Процессы сопоставляются сначала как тот же экземпляр (PID и время старта), затем
+// оставшиеся — по exe и командной строке: так перезапущенный процесс не считается
+// исчезнувшим и появившимся. CPU% в снимке мгновенный, поэтому шумный.
+func (s *Store) Diff(from, to string, opt DiffOptions) (Diff, error) {
+	a, err := s.resolve(from)
+	if err != nil {
+		return Diff{}, err
+	}
+	b, err := s.resolve(to)
+	if err != nil {
+		return Diff{}, err
+	}
+	return diffSnapshots(a, b, opt), nil
+}
+
+func diffSnapshots(a, b Snapshot, opt DiffOptions) Diff {
+	if opt.MinCPU <= 0 {
+		opt.MinCPU = defaultMinCPU
+	}
+	if opt.MinRSSMB == 0 {
+		opt.MinRSSMB = defaultMinRSSMB
+	}
+	d := Diff{
+		From:        a.Info,
+		To:          b.Info,
+		Appeared:    []Process{},
+		Disappeared: []Process{},
+		Changed:     []Change{},
+	}
+
+	byPID := make(map[int32]int, len(a.Processes))
+	for i, p := range a.Processes {
+		byPID[p.PID] = i
+	}
+	matched := make([]bool, len(a.Processes))
+	var rest []Process
+	for _, p := range b.Processes {
+		i, ok := byPID[p.PID]
+		if !ok || !sameInstance(a.Processes[i], p) {
+			rest = append(rest, p)
+			continue
+		}
+		matched[i] = true
+		d.compare(a.Processes[i], p, false, opt)
+	}
+
+	// Оставшиеся — по программе; одинаковые процессы парами в порядке PID.
+	byProgram := make(map[string][]Process)
+	for i, p := range a.Processes {
+		if !matched[i] {
+			byProgram[programKey(p)] = append(byProgram[programKey(p)], p)
+		}
+	}
+	for _, p := range rest {
+		k := programKey(p)
+		if prev := byProgram[k]; len(prev) > 0 {
+			byProgram[k] = prev[1:]
+			d.compare(prev[0], p, true, opt)
+			continue
+		}
+		d.Appeared = append(d.Appeared, p)
+	}
+	for _, list := range byProgram {
+		d.Disappeared = append(d.Disappeared, list...)
+	}
+
+	var before, after int64
+	for _, p := range a.Processes {
+		before += int64(p.RSSMB)
+	}
+	for _, p := range b.Processes {
+		after += int64(p.RSSMB)
+	}
+	d.RSSDeltaMB = after - before
+
+	for _, list := range [][]Process{d.Appeared, d.Disappeared} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].RSSMB != list[j].RSSMB {
+				return list[i].RSSMB > list[j].RSSMB
+			}
+			return list[i].PID < list[j].PID
+		})
+	}
+	sort.Slice(d.Changed, func(i, j int) bool {
+		x, y := d.Changed[i], d.Changed[j]
+		if dx, dy := abs(x.RSSDeltaMB), abs(y.RSSDeltaMB); dx != dy {
+			return dx > dy
+		}
+		if dx, dy := math.Abs(x.CPUDelta), math.Abs(y.CPUDelta); dx != dy {
+			return dx > dy
+		}
+		return x.PID < y.PID
+	})
+	return d
+}
+
+// sameInstance — prev и cur с одним PID — тот же экземпляр процесса. Время старта
+// сравнивается с точностью до секунды, как в samePIDOwner у watchlist: оно считается от
+// времени загрузки, которое между замерами сдвигается на секунду. Без времени старта PID
+// мог достаться другому процессу; имя сравниваем только тогда: потоки ядра (kworker)
+// меняют его на ходу.
+func sameInstance(prev, cur Process) bool {
+	if prev.StartTime == 0 || cur.StartTime == 0 {
+		return prev.StartTime == cur.StartTime && prev.Name == cur.Name
+	}
+	d := cur.StartTime - prev.StartTime
+	return d >= -1 && d <= 1
+}
+
+// compare добавляет пару процессов в Changed или считает её неизменившейся.
+func (d *Diff) compare(prev, cur Process, restarted bool, opt DiffOptions) {
+	c := Change{
+		PID:         cur.PID,
+		Name:        cur.Name,
+		Cmdline:     cur.Cmdline,
+		Restarted:   restarted,
+		CPUBefore:   prev.CPUPercent,
+		CPUAfter:    cur.CPUPercent,
+		CPUDelta:    cur.CPUPercent - prev.CPUPercent,
+		RSSBeforeMB: prev.RSSMB,
+		RSSAfterMB:  cur.RSSMB,
+		RSSDeltaMB:  int64(cur.RSSMB) - int64(prev.RSSMB),
+	}
+	if restarted {
+		c.PrevPID = prev.PID
+	}
+	if !restarted && math.Abs(c.CPUDelta) < opt.MinCPU && uint64(abs(c.RSSDeltaMB)) < opt.MinRSSMB {
+		d.Unchanged++
+		return
+	}
+	d.Changed = append(d.Changed, c)
+}
+
+// programKey — чем процесс отличается от других, если это не тот же экземпляр.
+func programKey(p Process) string {
+	prog := p.Exe
+	if prog == "" {
+		prog = p.Name
+	}
+	return prog + "\x00" + p.Cmdline
+}
+
+func (s *Store) resolve(name string) (Snapshot, error) {
+	if name == Now {
+		return s.current(time.Now()), nil
+	}
+	return s.Get(name)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,107 @@
+package snapshots
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	nginx := Process{PID: 100, Name: "nginx", Exe: "/usr/sbin/nginx", Cmdline: "nginx -g daemon off;", StartTime: 1000, RSSMB: 50}
+	tests := []struct {
+		name                  string
+		from, to              []Process
+		appeared, disappeared []int32
+		changed               []int32
+		restarted             []bool
+		unchanged             int
+	}{
+		{
+			name:      "same instance",
+			from:      []Process{nginx},
+			to:        []Process{nginx},
+			unchanged: 1,
+		},
+		{
+			name:      "start time shifted by a second",
+			from:      []Process{nginx},
+			to:        []Process{{PID: 100, Name: "nginx", Exe: "/usr/sbin/nginx", StartTime: 1001, RSSMB: 52}},
+			unchanged: 1,
+		},
+		{
+			name:        "pid reused by another program",
+			from:        []Process{nginx},
+			to:          []Process{{PID: 100, Name: "bash", Exe: "/bin/bash", StartTime: 1500, RSSMB: 5}},
+			appeared:    []int32{100},
+			disappeared: []int32{100},
+		},
+		{
+			name:      "restarted under a new pid",
+			from:      []Process{nginx},
+			to:        []Process{{PID: 200, Name: "nginx", Exe: "/usr/sbin/nginx", Cmdline: "nginx -g daemon off;", StartTime: 1500, RSSMB: 50}},
+			changed:   []int32{200},
+			restarted: []bool{true},
+		},
+		{
+			name:      "restarted under the same pid",
+			from:      []Process{nginx},
+			to:        []Process{{PID: 100, Name: "nginx", Exe: "/usr/sbin/nginx", Cmdline: "nginx -g daemon off;", StartTime: 1500, RSSMB: 50}},
+			changed:   []int32{100},
+			restarted: []bool{true},
+		},
+		{
+			name:      "memory growth",
+			from:      []Process{nginx, {PID: 7, Name: "db", StartTime: 10, RSSMB: 100}},
+			to:        []Process{{PID: 7, Name: "db", StartTime: 10, RSSMB: 400, CPUPercent: 1}, {PID: 100, Name: "nginx", Exe: "/usr/sbin/nginx", Cmdline: "nginx -g daemon off;", StartTime: 1000, RSSMB: 50, CPUPercent: 30}},
+			changed:   []int32{7, 100},
+			restarted: []bool{false, false},
+		},
+		{
+			// Без времени старта PID сопоставляется только вместе с именем.
+			name:        "no start time and another name",
+			from:        []Process{{PID: 5, Name: "kworker/0:1"}},
+			to:          []Process{{PID: 5, Name: "kworker/0:1-events"}},
+			appeared:    []int32{5},
+			disappeared: []int32{5},
+		},
+	}
+	for _, tt := range tests {
+		d := diffSnapshots(Snapshot{Processes: tt.from}, Snapshot{Processes: tt.to}, DiffOptions{})
+		var appeared, disappeared, changed []int32
+		var restarted []bool
+		for _, p := range d.Appeared {
+			appeared = append(appeared, p.PID)
+		}
+		for _, p := range d.Disappeared {
+			disappeared = append(disappeared, p.PID)
+		}
+		for _, c := range d.Changed {
+			changed = append(changed, c.PID)
+			restarted = append(restarted, c.Restarted)
+		}
+		if !slices.Equal(appeared, tt.appeared) || !slices.Equal(disappeared, tt.disappeared) ||
+			!slices.Equal(changed, tt.changed) || !slices.Equal(restarted, tt.restarted) || d.Unchanged != tt.unchanged {
+			t.Errorf("%s: appeared %v, disappeared %v, changed %v (restarted %v), unchanged %d",
+				tt.name, appeared, disappeared, changed, restarted, d.Unchanged)
+		}
+	}
+}
+
+func TestSameInstance(t *testing.T) {
+	tests := []struct {
+		prev, cur Process
+		want      bool
+	}{
+		{Process{StartTime: 1000}, Process{StartTime: 1000}, true},
+		{Process{StartTime: 1000}, Process{StartTime: 999}, true},
+		{Process{StartTime: 1000}, Process{StartTime: 1001}, true},
+		{Process{StartTime: 1000}, Process{StartTime: 1002}, false},
+		{Process{StartTime: 1000}, Process{}, false},
+		{Process{Name: "init"}, Process{Name: "init"}, true},
+		{Process{Name: "a"}, Process{Name: "b"}, false},
+	}
+	for _, tt := range tests {
+		if got := sameInstance(tt.prev, tt.cur); got != tt.want {
+			t.Errorf("sameInstance(%+v, %+v) = %v, want %v", tt.prev, tt.cur, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/store"
+)
+
+const (
+	dirName = "snapshots"
+	// Now — имя, под которым в Diff подразумевается текущая таблица процессов.
+	Now = "now"
+)
+
+var (
+	ErrNotFound = fmt.Errorf("snapshot %w", store.ErrNotFound)
+	ErrExists   = fmt.Errorf("snapshot %w", store.ErrExists)
+	ErrInvalid  = fmt.Errorf("%w snapshot name", store.ErrInvalid)
+)
+
+// Имя снимка — это имя файла, поэтому только безопасные символы.
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Process — процесс в снимке.
+type Process struct {
+	PID          int32   `json:"pid"`
+	Name         string  `json:"name"`
+	Exe          string  `json:"exe,omitempty"`
+	Cmdline      string  `json:"cmdline,omitempty"`
+	Username     string  `json:"username,omitempty"`
+	StartTime    int64   `json:"start_time,omitempty"` // unix, секунды
+	CPUPercent   float64 `json:"cpu_percent"`
+	RSSMB        uint64  `json:"rss_mb"`
+	Threads      int32   `json:"threads,omitempty"`
+	FDs          int32   `json:"fds,omitempty"`
+	DiskReadBps  float64 `json:"disk_read_bps,omitempty"`
+	DiskWriteBps float64 `json:"disk_write_bps,omitempty"`
+}
+
+// Info — сведения о снимке без списка процессов.
+type Info struct {
+	Name          string  `json:"name"`
+	Time          int64   `json:"time"` // unix, секунды
+	Hostname      string  `json:"hostname,omitempty"`
+	ProcessCount  int     `json:"process_count"`
+	CPUPercent    float64 `json:"cpu_percent"` // загрузка системы в момент снимка
+	MemoryUsedMB  uint64  `json:"memory_used_mb"`
+	MemoryTotalMB uint64  `json:"memory_total_mb"`
+}
+
+// Snapshot — таблица процессов в момент Time; хранится в data-dir/snapshots/<name>.json.
+type Snapshot struct {
+	Info
+	Processes []Process `json:"processes"` // по PID
+}
+
+// Store — именованные снимки в каталоге данных.
+type Store struct {
+	collector *monitor.Collector
+	dir       string
+
+	mu    sync.Mutex
+	infos map[string]Info
+}
+
+// Load читает сведения о снимках из dataDir; если каталога нет — снимков нет.
+// Испорченные файлы пропускаются.
+func Load(dataDir string, collector *monitor.Collector) (*Store, error) {
+	s := &Store{
+		collector: collector,
+		dir:       filepath.Join(dataDir, dirName),
+		infos:     make(map[string]Info),
+	}
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() || validName(name) != nil {
+			continue
+		}
+		snap, err := s.read(name)
+		if err != nil {
+			continue
+		}
+		s.infos[name] = snap.Info
+	}
+	return s, nil
+}
+
+func validName(name string) error {
+	if strings.EqualFold(name, Now) {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalid, Now)
+	}
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("%w: use up to 64 letters, digits, '.', '_' or '-'", ErrInvalid)
+	}
+	return nil
+}
+
+// List возвращает сведения о снимках, от новых к старым.
+func (s *Store) List() []Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Info, 0, len(s.infos))
+	for _, info := range s.infos {
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Time != out[j].Time {
+			return out[i].Time > out[j].Time
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Capture сохраняет текущую таблицу процессов под именем name; пустое имя — по времени
+// снимка. Существующий снимок не перезаписывается (ErrExists).
+func (s *Store) Capture(name string) (Info, error) {
+	now := time.Now()
+	if name == "" {
+		name = now.Format("2006-01-02T15-04-05")
+	}
+	if err := validName(name); err != nil {
+		return Info{}, err
+	}
+	snap := s.current(now)
+	snap.Name = name
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.infos[name]; ok {
+		return Info{}, fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	if err := s.write(snap); err != nil {
+		return Info{}, err
+	}
+	s.infos[name] = snap.Info
+	return snap.Info, nil
+}
+
+// Get возвращает снимок name целиком.
+func (s *Store) Get(name string) (Snapshot, error) {
+	s.mu.Lock()
+	_, ok := s.infos[name]
+	s.mu.Unlock()
+	if !ok {
+		return Snapshot{}, ErrNotFound
+	}
+	return s.read(name)
+}
+
+// Delete удаляет снимок name.
+func (s *Store) Delete(name string) (Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, ok := s.infos[name]
+	if !ok {
+		return Info{}, ErrNotFound
+	}
+	if err := os.Remove(s.path(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Info{}, err
+	}
+	delete(s.infos, name)
+	return info, nil
+}
+
+// current снимает таблицу процессов с последнего тика коллектора.
+func (s *Store) current(now time.Time) Snapshot {
+	stats := s.collector.Get()
+	idents := s.collector.ProcessIdentities()
+	pids := make([]int32, 0, len(idents))
+	for _, id := range idents {
+		pids = append(pids, id.PID)
+	}
+	usage := make(map[int32]monitor.ProcessUsage, len(idents))
+	for _, u := range s.collector.ProcessUsage(pids) {
+		usage[u.PID] = u
+	}
+	snap := Snapshot{
+		Info: Info{
+			Name:          Now,
+			Time:          now.Unix(),
+			Hostname:      stats.Hostname,
+			CPUPercent:    stats.CPUPercent,
+			MemoryUsedMB:  stats.MemoryUsedMB,
+			MemoryTotalMB: stats.MemoryTotalMB,
+		},
+		Processes: make([]Process, 0, len(idents)),
+	}
+	for _, id := range idents {
+		u, ok := usage[id.PID]
+		if !ok {
+			continue // завершился, пока читали потоки и дескрипторы
+		}
+		snap.Processes = append(snap.Processes, Process{
+			PID:          id.PID,
+			Name:         id.Name,
+			Exe:          id.Exe,
+			Cmdline:      id.Cmdline,
+			Username:     id.Username,
+			StartTime:    id.StartTime,
+			CPUPercent:   u.CPUPercent,
+			RSSMB:        u.RSSMB,
+			Threads:      u.Threads,
+			FDs:          u.FDs,
+			DiskReadBps:  u.DiskReadBps,
+			DiskWriteBps: u.DiskWriteBps,
+		})
+	}
+	sort.Slice(snap.Processes, func(i, j int) bool { return snap.Processes[i].PID < snap.Processes[j].PID })
+	snap.ProcessCount = len(snap.Processes)
+	return snap
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s *Store) read(name string) (Snapshot, error) {
+	b, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, ErrNotFound
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", s.path(name), err)
+	}
+	snap.Name = name
+	return snap, nil
+}
+
+// write сохраняет снимок через временный файл. Командные строки могут содержать секреты,
+// поэтому файл доступен только владельцу.
+func (s *Store) write(snap Snapshot) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return store.WriteFile(s.path(snap.Name), b, 0600)
+}
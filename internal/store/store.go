@@ -0,0 +1,89 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Ошибки хранилищ; пакеты оборачивают их своими, например "rule not found".
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid")
+	ErrExists   = errors.New("already exists")
+)
+
+// NewID возвращает случайный идентификатор записи из 12 hex-символов.
+func NewID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WriteFile записывает b в path через временный файл, чтобы при сбое не остался
+// наполовину записанный файл. Недостающие каталоги создаются.
+func WriteFile(path string, b []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SaveJSON записывает v в path как JSON с отступами (см. WriteFile).
+func SaveJSON(path string, v any, perm os.FileMode) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, b, perm)
+}
+
+// LoadList читает JSON-массив из path; если файла нет — пустой список. Элементы,
+// не прошедшие validate, пропускаются, остальные загружаются. Следующее сохранение
+// перепишет файл без них, поэтому в этом случае (и если файл не разобрать) исходный
+// файл копируется рядом (см. Backup), а ошибка перечисляет пропущенное.
+func LoadList[T any](path string, validate func(T) error) ([]T, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var all []T
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, errors.Join(fmt.Errorf("%s: %w", path, err), Backup(path, b))
+	}
+	out := make([]T, 0, len(all))
+	var errs []error
+	for i, v := range all {
+		if err := validate(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: entry %d skipped: %w", path, i, err))
+			continue
+		}
+		out = append(out, v)
+	}
+	if len(errs) > 0 {
+		errs = append(errs, Backup(path, b))
+	}
+	return out, errors.Join(errs...)
+}
+
+// Backup сохраняет содержимое b файла path в path.<время>.bak, чтобы его не потеряло
+// следующее сохранение, и возвращает ошибку с именем копии.
+func Backup(path string, b []byte) error {
+	bak := path + "." + time.Now().Format("20060102-150405.000") + ".bak"
+	if err := os.WriteFile(bak, b, 0600); err != nil {
+		return fmt.Errorf("%s: backup: %w", path, err)
+	}
+	return fmt.Errorf("%s: original saved to %s", path, bak)
+}
@@ -0,0 +1,487 @@
+package watchlist
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GalitskyKK/nekkus-eye/internal/monitor"
+	"github.com/GalitskyKK/nekkus-eye/internal/store"
+)
+
+const (
+	fileName = "watchlist.json"
+	// Замер раз в sampleInterval, история — сутки.
+	sampleInterval = 5 * time.Second
+	historySize    = 17280
+)
+
+// Способы найти процесс (Entry.Kind).
+const (
+	KindPID     = "pid"     // Pattern — PID; после перезапуска процесс находится по exe и cmdline
+	KindExe     = "exe"     // Pattern — полный путь к исполняемому файлу
+	KindName    = "name"    // Pattern — имя процесса или файла, без учёта регистра
+	KindCmdline = "cmdline" // Pattern — регулярное выражение по командной строке
+)
+
+var (
+	ErrNotFound = fmt.Errorf("watch entry %w", store.ErrNotFound)
+	ErrInvalid  = fmt.Errorf("%w watch entry", store.ErrInvalid)
+)
+
+// Entry — запись списка наблюдения, хранится в data-dir/watchlist.json.
+type Entry struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	Label   string `json:"label,omitempty"`
+	// Для kind=pid — exe, cmdline и время старта процесса на момент добавления.
+	Exe       string `json:"exe,omitempty"`
+	Cmdline   string `json:"cmdline,omitempty"`
+	StartTime int64  `json:"start_time,omitempty"` // unix, секунды
+	CreatedAt int64  `json:"created_at"`           // unix, секунды
+}
+
+// Title — подпись записи для виджета.
+func (e Entry) Title() string {
+	if e.Label != "" {
+		return e.Label
+	}
+	return e.Kind + ": " + e.Pattern
+}
+
+func (e Entry) validate() error {
+	switch e.Kind {
+	case KindPID:
+		if pid, err := strconv.ParseInt(e.Pattern, 10, 32); err != nil || pid <= 0 {
+			return fmt.Errorf("%w: pattern must be a pid", ErrInvalid)
+		}
+	case KindExe, KindName:
+		if strings.TrimSpace(e.Pattern) == "" {
+			return fmt.Errorf("%w: empty pattern", ErrInvalid)
+		}
+	case KindCmdline:
+		if _, err := regexp.Compile(e.Pattern); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be pid, exe, name or cmdline", ErrInvalid)
+	}
+	return nil
+}
+
+// Sample — суммарное потребление всех процессов записи в один момент.
+// Пустой PIDs — ни одного подходящего процесса не было (разрыв на графике).
+type Sample struct {
+	Time         int64   `json:"time"` // unix, мс
+	PIDs         []int32 `json:"pids,omitempty"`
+	CPUPercent   float64 `json:"cpu_percent"`
+	RSSMB        uint64  `json:"rss_mb"`
+	Threads      int32   `json:"threads"`
+	FDs          int32   `json:"fds"`
+	DiskReadBps  float64 `json:"disk_read_bps"`
+	DiskWriteBps float64 `json:"disk_write_bps"`
+}
+
+// Status — запись вместе с последним замером.
+type Status struct {
+	Entry
+	Running bool `json:"running"`
+	// Restarts — сколько раз процессы записи сменились целиком (перезапуск) с момента старта Eye.
+	Restarts  int     `json:"restarts"`
+	PeakRSSMB uint64  `json:"peak_rss_mb"`
+	Latest    *Sample `json:"latest,omitempty"`
+}
+
+// state — история и состояние сопоставления записи; в файл не сохраняется.
+type state struct {
+	re       *regexp.Regexp
+	history  []Sample
+	pids     []int32
+	everRan  bool
+	restarts int
+	peakRSS  uint64
+}
+
+// Watchlist — список наблюдения с историей замеров по каждой записи.
+type Watchlist struct {
+	collector *monitor.Collector
+	path      string
+
+	mu      sync.Mutex
+	entries []Entry
+	states  map[string]*state
+}
+
+// Load читает список из dataDir; если файла нет — список пуст. Испорченные записи
+// пропускаются и перечисляются в ошибке, исходный файл сохраняется рядом.
+func Load(dataDir string, collector *monitor.Collector) (*Watchlist, error) {
+	w := &Watchlist{
+		collector: collector,
+		path:      filepath.Join(dataDir, fileName),
+		entries:   []Entry{},
+		states:    make(map[string]*state),
+	}
+	entries, err := store.LoadList(w.path, Entry.validate)
+	for _, e := range entries {
+		w.entries = append(w.entries, e)
+		w.states[e.ID] = newState(e)
+	}
+	return w, err
+}
+
+func newState(e Entry) *state {
+	st := &state{}
+	if e.Kind == KindCmdline {
+		st.re = regexp.MustCompile(e.Pattern)
+	}
+	return st
+}
+
+// Run делает замеры раз в sampleInterval, пока ctx не отменён.
+func (w *Watchlist) Run(ctx context.Context) {
+	t := time.NewTicker(sampleInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			w.sample()
+		}
+	}
+}
+
+// List возвращает все записи с последними замерами.
+func (w *Watchlist) List() []Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]Status, 0, len(w.entries))
+	for _, e := range w.entries {
+		out = append(out, w.statusLocked(e))
+	}
+	return out
+}
+
+// Get возвращает запись id с последним замером.
+func (w *Watchlist) Get(id string) (Status, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := w.indexLocked(id)
+	if i < 0 {
+		return Status{}, ErrNotFound
+	}
+	return w.statusLocked(w.entries[i]), nil
+}
+
+// History возвращает замеры записи id начиная с since; при points > 0 история
+// прореживается до points точек.
+func (w *Watchlist) History(id string, since time.Time, points int) ([]Sample, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st, ok := w.states[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := make([]Sample, 0)
+	for _, s := range st.history {
+		if s.Time >= since.UnixMilli() {
+			out = append(out, s)
+		}
+	}
+	if points > 0 && len(out) > points {
+		step := float64(len(out)) / float64(points)
+		thinned := make([]Sample, 0, points)
+		for i := 0; i < points; i++ {
+			thinned = append(thinned, out[int(float64(i)*step)])
+		}
+		// Последний замер оставляем всегда.
+		thinned[len(thinned)-1] = out[len(out)-1]
+		out = thinned
+	}
+	return out, nil
+}
+
+// Add добавляет запись. Для kind=pid запоминает exe и cmdline процесса,
+// чтобы найти его после перезапуска; если процесса нет — monitor.ErrProcessNotFound.
+func (w *Watchlist) Add(e Entry) (Entry, error) {
+	e.ID = store.NewID()
+	e.CreatedAt = time.Now().Unix()
+	if err := w.prepare(&e); err != nil {
+		return Entry{}, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	next := append(slices.Clone(w.entries), e)
+	if err := w.save(next); err != nil {
+		return Entry{}, err
+	}
+	w.entries = next
+	w.states[e.ID] = newState(e)
+	return e, nil
+}
+
+// Update применяет fn к копии записи id и сохраняет результат. ID и время создания
+// не меняются; если поменялся способ поиска, история записи начинается заново.
+func (w *Watchlist) Update(id string, fn func(*Entry) error) (Entry, error) {
+	w.mu.Lock()
+	i := w.indexLocked(id)
+	if i < 0 {
+		w.mu.Unlock()
+		return Entry{}, ErrNotFound
+	}
+	prev := w.entries[i]
+	w.mu.Unlock()
+
+	next := prev
+	if err := fn(&next); err != nil {
+		return Entry{}, err
+	}
+	next.ID, next.CreatedAt = prev.ID, prev.CreatedAt
+	retarget := next.Kind != prev.Kind || next.Pattern != prev.Pattern
+	if retarget {
+		next.Exe, next.Cmdline, next.StartTime = "", "", 0
+		if err := w.prepare(&next); err != nil {
+			return Entry{}, err
+		}
+	} else {
+		next.Exe, next.Cmdline, next.StartTime = prev.Exe, prev.Cmdline, prev.StartTime
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if i = w.indexLocked(id); i < 0 {
+		return Entry{}, ErrNotFound
+	}
+	entries := slices.Clone(w.entries)
+	entries[i] = next
+	if err := w.save(entries); err != nil {
+		return Entry{}, err
+	}
+	w.entries = entries
+	if retarget {
+		w.states[id] = newState(next)
+	}
+	return next, nil
+}
+
+// Delete удаляет запись id вместе с историей.
+func (w *Watchlist) Delete(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := w.indexLocked(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	next := slices.Delete(slices.Clone(w.entries), i, i+1)
+	if err := w.save(next); err != nil {
+		return err
+	}
+	w.entries = next
+	delete(w.states, id)
+	return nil
+}
+
+// prepare проверяет запись и для kind=pid заполняет exe, cmdline и время старта.
+func (w *Watchlist) prepare(e *Entry) error {
+	e.Pattern = strings.TrimSpace(e.Pattern)
+	if err := e.validate(); err != nil {
+		return err
+	}
+	if e.Kind != KindPID {
+		return nil
+	}
+	pid, _ := strconv.ParseInt(e.Pattern, 10, 32)
+	for _, id := range w.collector.ProcessIdentities() {
+		if id.PID == int32(pid) {
+			e.Exe, e.Cmdline, e.StartTime = id.Exe, id.Cmdline, id.StartTime
+			if e.Label == "" {
+				e.Label = id.Name
+			}
+			return nil
+		}
+	}
+	return monitor.ErrProcessNotFound
+}
+
+func (w *Watchlist) indexLocked(id string) int {
+	return slices.IndexFunc(w.entries, func(e Entry) bool { return e.ID == id })
+}
+
+func (w *Watchlist) statusLocked(e Entry) Status {
+	s := Status{Entry: e}
+	if st, ok := w.states[e.ID]; ok {
+		s.Running = len(st.pids) > 0
+		s.Restarts = st.restarts
+		s.PeakRSSMB = st.peakRSS
+		if n := len(st.history); n > 0 {
+			latest := st.history[n-1]
+			s.Latest = &latest
+		}
+	}
+	return s
+}
+
+// sample находит процессы каждой записи и добавляет в историю их суммарное потребление.
+func (w *Watchlist) sample() {
+	w.mu.Lock()
+	entries := slices.Clone(w.entries)
+	res := make(map[string]*regexp.Regexp, len(entries))
+	for _, e := range entries {
+		if st := w.states[e.ID]; st != nil {
+			res[e.ID] = st.re
+		}
+	}
+	w.mu.Unlock()
+	if len(entries) == 0 {
+		return
+	}
+
+	idents := w.collector.ProcessIdentities()
+	matched := make(map[string][]int32, len(entries))
+	rebound := make(map[string]monitor.ProcessIdentity) // id → новый процесс для kind=pid
+	var all []int32
+	for _, e := range entries {
+		pids, next := match(e, res[e.ID], idents)
+		if next != nil {
+			rebound[e.ID] = *next
+		}
+		matched[e.ID] = pids
+		all = append(all, pids...)
+	}
+	usage := make(map[int32]monitor.ProcessUsage, len(all))
+	for _, u := range w.collector.ProcessUsage(all) {
+		usage[u.PID] = u
+	}
+
+	now := time.Now().UnixMilli()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, e := range entries {
+		st, ok := w.states[e.ID]
+		if !ok {
+			continue // запись удалили, пока шёл замер
+		}
+		s := Sample{Time: now}
+		for _, pid := range matched[e.ID] {
+			u, ok := usage[pid]
+			if !ok {
+				continue
+			}
+			s.PIDs = append(s.PIDs, pid)
+			s.CPUPercent += u.CPUPercent
+			s.RSSMB += u.RSSMB
+			s.Threads += u.Threads
+			s.FDs += u.FDs
+			s.DiskReadBps += u.DiskReadBps
+			s.DiskWriteBps += u.DiskWriteBps
+		}
+		if len(s.PIDs) > 0 {
+			if st.everRan && !overlaps(st.pids, s.PIDs) {
+				st.restarts++
+			}
+			st.everRan = true
+		}
+		st.pids = s.PIDs
+		st.peakRSS = max(st.peakRSS, s.RSSMB)
+		st.history = append(st.history, s)
+		// Обрезаем пачкой, чтобы не копировать историю на каждом замере.
+		if len(st.history) > historySize+historySize/8 {
+			st.history = append(st.history[:0:0], st.history[len(st.history)-historySize:]...)
+		}
+	}
+	if len(rebound) > 0 {
+		next := slices.Clone(w.entries)
+		for i, e := range next {
+			if id, ok := rebound[e.ID]; ok && e.Kind == KindPID {
+				next[i].Pattern = strconv.Itoa(int(id.PID))
+				next[i].StartTime = id.StartTime
+			}
+		}
+		// Если сохранить не удалось, новый PID всё равно используется до перезапуска Eye.
+		_ = w.save(next)
+		w.entries = next
+	}
+}
+
+// match возвращает PID процессов записи. Для kind=pid, если процесс с этим PID
+// завершился (или PID занят другой программой), ищет процесс с теми же exe и cmdline
+// и возвращает его в next.
+func match(e Entry, re *regexp.Regexp, idents []monitor.ProcessIdentity) (pids []int32, next *monitor.ProcessIdentity) {
+	switch e.Kind {
+	case KindPID:
+		pid, _ := strconv.ParseInt(e.Pattern, 10, 32)
+		for _, id := range idents {
+			if id.PID == int32(pid) && samePIDOwner(e, id) {
+				return []int32{id.PID}, nil
+			}
+		}
+		if e.Exe == "" {
+			return nil, nil
+		}
+		for _, id := range idents {
+			if sameExe(id.Exe, e.Exe) && id.Cmdline == e.Cmdline {
+				return []int32{id.PID}, &id
+			}
+		}
+	case KindExe:
+		for _, id := range idents {
+			if id.Exe != "" && sameExe(id.Exe, e.Pattern) {
+				pids = append(pids, id.PID)
+			}
+		}
+	case KindName:
+		for _, id := range idents {
+			if strings.EqualFold(id.Name, e.Pattern) || (id.Exe != "" && strings.EqualFold(filepath.Base(id.Exe), e.Pattern)) {
+				pids = append(pids, id.PID)
+			}
+		}
+	case KindCmdline:
+		for _, id := range idents {
+			if re != nil && id.Cmdline != "" && re.MatchString(id.Cmdline) {
+				pids = append(pids, id.PID)
+			}
+		}
+	}
+	return pids, nil
+}
+
+// samePIDOwner сообщает, что процесс id с PID записи — тот же, что при добавлении, а не
+// новый процесс с освободившимся PID. Сравнивается exe; если его не прочитать (нет прав),
+// — время старта, а без него PID не доверяем. Время старта на Linux сдвигается вместе
+// с подводкой часов, поэтому допускаем секунду расхождения.
+func samePIDOwner(e Entry, id monitor.ProcessIdentity) bool {
+	if e.Exe != "" && id.Exe != "" {
+		return sameExe(id.Exe, e.Exe)
+	}
+	d := id.StartTime - e.StartTime
+	return e.StartTime != 0 && d >= -1 && d <= 1
+}
+
+func sameExe(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func overlaps(a, b []int32) bool {
+	for _, pid := range a {
+		if slices.Contains(b, pid) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watchlist) save(entries []Entry) error {
+	return store.SaveJSON(w.path, entries, 0644)
+}
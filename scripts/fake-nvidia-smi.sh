@@ -0,0 +1,83 @@
+#!/bin/sh
+# Фейковый nvidia-smi для проверки Eye без GPU NVIDIA:
+#   go run ./cmd/ --headless --nvidia-smi ./scripts/fake-nvidia-smi.sh
+# Отвечает на те же вызовы, что делает монитор: -L, --query-gpu (с --loop-ms), dmon, -q -x.
+
+gpus() {
+	case "$*" in
+	*clocks_throttle_reasons*)
+		echo "0, 00000000:01:00.0, NVIDIA GeForce RTX 3090, 35, 2048, 24576, 49, 120.50, 30, 350.00, 1695, 2100, 9751, 5, 0, 0x0000000000000004"
+		echo "1, 00000000:02:00.0, NVIDIA GeForce RTX 3090, 80, 20480, 24576, 71, 310.20, 65, 350.00, 1905, 2100, 9751, 0, 12, 0x0000000000000000"
+		;;
+	*)
+		echo "0, 00000000:01:00.0, NVIDIA GeForce RTX 3090, 35, 2048, 24576, 49, 120.50, 30"
+		echo "1, 00000000:02:00.0, NVIDIA GeForce RTX 3090, 80, 20480, 24576, 71, 310.20, 65"
+		;;
+	esac
+}
+
+pcie() {
+	echo "    0      12       3"
+	echo "    1     850     410"
+}
+
+case "$*" in
+-L)
+	echo "GPU 0: NVIDIA GeForce RTX 3090 (UUID: GPU-fake-0)"
+	echo "GPU 1: NVIDIA GeForce RTX 3090 (UUID: GPU-fake-1)"
+	;;
+*--query-gpu=*--loop-ms=*)
+	while :; do
+		gpus "$@"
+		sleep 1
+	done
+	;;
+*--query-gpu=*)
+	gpus "$@"
+	;;
+dmon*)
+	echo "# gpu   rxpci   txpci"
+	echo "# Idx    MB/s    MB/s"
+	case "$*" in
+	*-c\ 1*) pcie ;;
+	*)
+		while :; do
+			pcie
+			sleep 1
+		done
+		;;
+	esac
+	;;
+"-q -x")
+	cat <<XML
+<?xml version="1.0" ?>
+<!DOCTYPE nvidia_smi_log SYSTEM "nvsmi_device_v12.dtd">
+<nvidia_smi_log>
+	<gpu id="00000000:01:00.0">
+		<processes>
+			<process_info>
+				<pid>$$</pid>
+				<type>G</type>
+				<process_name>fake-nvidia-smi</process_name>
+				<used_memory>256 MiB</used_memory>
+			</process_info>
+		</processes>
+	</gpu>
+	<gpu id="00000000:02:00.0">
+		<processes>
+			<process_info>
+				<pid>$PPID</pid>
+				<type>C</type>
+				<process_name>nekkus-eye</process_name>
+				<used_memory>18432 MiB</used_memory>
+			</process_info>
+		</processes>
+	</gpu>
+</nvidia_smi_log>
+XML
+	;;
+*)
+	echo "fake nvidia-smi: unsupported args: $*" >&2
+	exit 1
+	;;
+esac